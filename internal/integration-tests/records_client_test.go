@@ -180,14 +180,16 @@ func Test_recordsClient_Insert_Get(t *testing.T) {
 		assert.ElementsMatch(t, insertRecordRequest.Body[multipleColumn].StringList, record.Data[multipleColumn])
 		assert.Equal(t, insertRecordRequest.Body[jsonColumn].String, record.Data[jsonColumn])
 
-		record, err = recordsCli.Upsert(ctx, xata.UpsertRecordRequest{
+		upserted, err := xata.UpsertWithStatus(ctx, recordsCli, xata.UpsertRecordRequest{
 			RecordRequest: insertRecordRequest.RecordRequest,
 			RecordID:      providedRecordID,
 			Columns:       insertRecordRequest.Columns,
 			Body:          insertRecordRequest.Body,
 		})
 		assert.NoError(t, err)
-		assert.NotNil(t, record)
+		assert.NotNil(t, upserted)
+		assert.False(t, upserted.Created)
+		record = &upserted.Record
 		assert.Equal(t, insertRecordRequest.Body[emailColumn].String, record.Data[emailColumn])
 		assert.Equal(t, insertRecordRequest.Body[boolColumn].Boolean, record.Data[boolColumn])
 		assert.Equal(t, insertRecordRequest.Body[stringColumn].String, record.Data[stringColumn])
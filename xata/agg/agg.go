@@ -0,0 +1,385 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package agg provides a fluent builder for the aggregations of xata.SearchAndFilterClient.Aggregate.
+//
+//	aggs, err := agg.Map{
+//		"total": agg.Count(),
+//		"byDay": agg.DateHistogram("createdAt").Interval("1d").
+//			Sub("revenue", agg.Sum("amount")).
+//			Sub("p", agg.Percentiles("amount", 50, 99)),
+//	}.Build()
+//
+// The required fields of every aggregation are validated by Build, so that an invalid
+// aggregation fails before the request is sent.
+package agg
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/xataio/xata-go/xata"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+// maxTopValuesSize is the maximum number of unique values returned by a top values aggregation.
+const maxTopValuesSize = 1000
+
+var (
+	intervalPattern = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d)$`)
+	timezonePattern = regexp.MustCompile(`^[+-][0-9]{2}:[0-9]{2}$`)
+)
+
+// Builder builds a single aggregation.
+type Builder interface {
+	Build() (xata.AggExpression, error)
+}
+
+// Map is a set of named aggregations.
+type Map map[string]Builder
+
+// Build validates and builds all the aggregations of the map.
+func (m Map) Build() (xata.AggExpressionMap, error) {
+	aggs := make(xata.AggExpressionMap, len(m))
+	for _, name := range sortedNames(m) {
+		exp, err := build(name, m[name])
+		if err != nil {
+			return nil, err
+		}
+		aggs[name] = exp
+	}
+	return aggs, nil
+}
+
+func build(name string, b Builder) (xata.AggExpression, error) {
+	if name == "" {
+		return nil, fmt.Errorf("aggregation name cannot be empty")
+	}
+	if b == nil {
+		return nil, fmt.Errorf("%s: aggregation cannot be nil", name)
+	}
+
+	exp, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return exp, nil
+}
+
+func sortedNames(m Map) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// subAggs holds the sub-aggregations of the bucket aggregations.
+type subAggs struct {
+	subs Map
+}
+
+func (s *subAggs) add(name string, b Builder) {
+	if s.subs == nil {
+		s.subs = Map{}
+	}
+	s.subs[name] = b
+}
+
+func (s *subAggs) build() (*xata.NestedAggsMap, error) {
+	if len(s.subs) == 0 {
+		return nil, nil
+	}
+
+	nested := make(xata.NestedAggsMap, len(s.subs))
+	for _, name := range sortedNames(s.subs) {
+		exp, err := build(name, s.subs[name])
+		if err != nil {
+			return nil, err
+		}
+		nested[name] = exp
+	}
+	return &nested, nil
+}
+
+func validateColumn(column string) error {
+	if column == "" {
+		return fmt.Errorf("column cannot be empty")
+	}
+	return nil
+}
+
+type CountBuilder struct {
+	filter *xata.FilterExpression
+}
+
+// Count counts the records, optionally only those matching a filter.
+func Count() *CountBuilder {
+	return &CountBuilder{}
+}
+
+// Filter only counts the records matching the filter.
+func (c *CountBuilder) Filter(filter *xata.FilterExpression) *CountBuilder {
+	c.filter = filter
+	return c
+}
+
+func (c *CountBuilder) Build() (xata.AggExpression, error) {
+	count := xata.CountAll()
+	if c.filter != nil {
+		count = xata.CountByFilter(xata.CountAggFilter{Filter: *c.filter})
+	}
+	return xata.NewCountAggExpression(xata.AggExpressionCount{Count: count}), nil
+}
+
+// metricBuilder builds the aggregations computing a single value from a column.
+type metricBuilder struct {
+	column string
+	build  func(column string) *xatagenworkspace.AggExpression
+}
+
+func (m *metricBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(m.column); err != nil {
+		return nil, err
+	}
+	return m.build(m.column), nil
+}
+
+// Sum sums the values of a numeric column.
+func Sum(column string) Builder {
+	return &metricBuilder{column: column, build: xata.NewSumAggExpression}
+}
+
+// Min returns the minimum value of a numeric or datetime column.
+func Min(column string) Builder {
+	return &metricBuilder{column: column, build: xata.NewMinAggExpression}
+}
+
+// Max returns the maximum value of a numeric or datetime column.
+func Max(column string) Builder {
+	return &metricBuilder{column: column, build: xata.NewMaxAggExpression}
+}
+
+// Average returns the average of the values of a numeric column.
+func Average(column string) Builder {
+	return &metricBuilder{column: column, build: xata.NewAverageAggExpression}
+}
+
+type UniqueCountBuilder struct {
+	column             string
+	precisionThreshold *int
+}
+
+// UniqueCount counts the unique values of a column.
+func UniqueCount(column string) *UniqueCountBuilder {
+	return &UniqueCountBuilder{column: column}
+}
+
+// PrecisionThreshold sets the count below which the unique count is expected to be exact.
+func (u *UniqueCountBuilder) PrecisionThreshold(threshold int) *UniqueCountBuilder {
+	u.precisionThreshold = &threshold
+	return u
+}
+
+func (u *UniqueCountBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(u.column); err != nil {
+		return nil, err
+	}
+	if u.precisionThreshold != nil && *u.precisionThreshold < 0 {
+		return nil, fmt.Errorf("precision threshold cannot be negative")
+	}
+
+	return xata.NewUniqueCountAggExpression(xata.UniqueCountAgg{
+		Column:             u.column,
+		PrecisionThreshold: u.precisionThreshold,
+	}), nil
+}
+
+type PercentilesBuilder struct {
+	column      string
+	percentiles []float64
+}
+
+// Percentiles computes the given percentiles of a numeric column.
+func Percentiles(column string, percentiles ...float64) *PercentilesBuilder {
+	return &PercentilesBuilder{column: column, percentiles: percentiles}
+}
+
+func (p *PercentilesBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(p.column); err != nil {
+		return nil, err
+	}
+	if len(p.percentiles) == 0 {
+		return nil, fmt.Errorf("at least one percentile is required")
+	}
+	for _, pc := range p.percentiles {
+		if pc < 0 || pc > 100 {
+			return nil, fmt.Errorf("percentile %v must be between 0 and 100", pc)
+		}
+	}
+
+	return xata.NewPercentilesAggExpression(xata.PercentilesAgg{
+		Column:      p.column,
+		Percentiles: p.percentiles,
+	}), nil
+}
+
+type DateHistogramBuilder struct {
+	subAggs
+	column           string
+	interval         *string
+	calendarInterval *xata.DateHistogramAggCalendarInterval
+	timezone         *string
+}
+
+// DateHistogram splits the records into buckets by a datetime column. Either Interval or
+// CalendarInterval must be set.
+func DateHistogram(column string) *DateHistogramBuilder {
+	return &DateHistogramBuilder{column: column}
+}
+
+// Interval sets a fixed interval formatted as number + units, for example: `5d`, `20m`, `10s`.
+func (d *DateHistogramBuilder) Interval(interval string) *DateHistogramBuilder {
+	d.interval = &interval
+	return d
+}
+
+// CalendarInterval sets a calendar-aware interval.
+func (d *DateHistogramBuilder) CalendarInterval(interval xata.DateHistogramAggCalendarInterval) *DateHistogramBuilder {
+	d.calendarInterval = &interval
+	return d
+}
+
+// Timezone sets the timezone as an ISO 8601 UTC offset, for example: `+01:00` or `-08:00`.
+func (d *DateHistogramBuilder) Timezone(timezone string) *DateHistogramBuilder {
+	d.timezone = &timezone
+	return d
+}
+
+// Sub adds a sub-aggregation computed for each bucket.
+func (d *DateHistogramBuilder) Sub(name string, b Builder) *DateHistogramBuilder {
+	d.add(name, b)
+	return d
+}
+
+func (d *DateHistogramBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(d.column); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case d.interval == nil && d.calendarInterval == nil:
+		return nil, fmt.Errorf("either interval or calendar interval is required")
+	case d.interval != nil && d.calendarInterval != nil:
+		return nil, fmt.Errorf("interval and calendar interval cannot be both set")
+	case d.interval != nil && !intervalPattern.MatchString(*d.interval):
+		return nil, fmt.Errorf("invalid interval %q, expected number + units, for example: 5d", *d.interval)
+	case d.calendarInterval != nil &&
+		(*d.calendarInterval < xata.DateHistogramAggCalendarIntervalMinute || *d.calendarInterval > xata.DateHistogramAggCalendarIntervalYear):
+		return nil, fmt.Errorf("invalid calendar interval %d", *d.calendarInterval)
+	case d.timezone != nil && !timezonePattern.MatchString(*d.timezone):
+		return nil, fmt.Errorf("invalid timezone %q, expected an UTC offset, for example: +01:00", *d.timezone)
+	}
+
+	nested, err := d.build()
+	if err != nil {
+		return nil, err
+	}
+
+	return xata.NewDateHistogramAggExpression(xata.DateHistogramAgg{
+		Aggs:             nested,
+		Column:           d.column,
+		Interval:         d.interval,
+		CalendarInterval: d.calendarInterval,
+		Timezone:         d.timezone,
+	}), nil
+}
+
+type TopValuesBuilder struct {
+	subAggs
+	column string
+	size   *int
+}
+
+// TopValues splits the records into buckets by the unique values of a column, ordered by count.
+func TopValues(column string) *TopValuesBuilder {
+	return &TopValuesBuilder{column: column}
+}
+
+// Size sets the maximum number of unique values to return.
+func (t *TopValuesBuilder) Size(size int) *TopValuesBuilder {
+	t.size = &size
+	return t
+}
+
+// Sub adds a sub-aggregation computed for each bucket.
+func (t *TopValuesBuilder) Sub(name string, b Builder) *TopValuesBuilder {
+	t.add(name, b)
+	return t
+}
+
+func (t *TopValuesBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(t.column); err != nil {
+		return nil, err
+	}
+	if t.size != nil && (*t.size < 1 || *t.size > maxTopValuesSize) {
+		return nil, fmt.Errorf("size must be between 1 and %d", maxTopValuesSize)
+	}
+
+	nested, err := t.build()
+	if err != nil {
+		return nil, err
+	}
+
+	return xata.NewTopValuesAggExpression(xata.TopValuesAgg{
+		Aggs:   nested,
+		Column: t.column,
+		Size:   t.size,
+	}), nil
+}
+
+type NumericHistogramBuilder struct {
+	subAggs
+	column   string
+	interval float64
+	offset   *float64
+}
+
+// NumericHistogram splits the records into buckets of the given interval by a numeric column.
+func NumericHistogram(column string, interval float64) *NumericHistogramBuilder {
+	return &NumericHistogramBuilder{column: column, interval: interval}
+}
+
+// Offset shifts the bucket boundaries, which start at 0 by default.
+func (n *NumericHistogramBuilder) Offset(offset float64) *NumericHistogramBuilder {
+	n.offset = &offset
+	return n
+}
+
+// Sub adds a sub-aggregation computed for each bucket.
+func (n *NumericHistogramBuilder) Sub(name string, b Builder) *NumericHistogramBuilder {
+	n.add(name, b)
+	return n
+}
+
+func (n *NumericHistogramBuilder) Build() (xata.AggExpression, error) {
+	if err := validateColumn(n.column); err != nil {
+		return nil, err
+	}
+	if n.interval <= 0 {
+		return nil, fmt.Errorf("interval must be greater than 0")
+	}
+
+	nested, err := n.build()
+	if err != nil {
+		return nil, err
+	}
+
+	return xata.NewNumericHistogramAggExpression(xata.NumericHistogramAgg{
+		Aggs:     nested,
+		Column:   n.column,
+		Interval: n.interval,
+		Offset:   n.offset,
+	}), nil
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package agg_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+	"github.com/xataio/xata-go/xata/agg"
+)
+
+func TestMap_Build(t *testing.T) {
+	t.Run("should build nested aggregations", func(t *testing.T) {
+		aggs, err := agg.Map{
+			"total": agg.Count(),
+			"byDay": agg.DateHistogram("createdAt").Interval("1d").Timezone("+01:00").
+				Sub("revenue", agg.Sum("amount")).
+				Sub("byStatus", agg.TopValues("status").Size(3).Sub("p", agg.Percentiles("amount", 50, 99))),
+			"prices": agg.NumericHistogram("price", 10).Offset(5).Sub("max", agg.Max("price")),
+			"users":  agg.UniqueCount("user").PrecisionThreshold(100),
+			"avg":    agg.Average("price"),
+			"min":    agg.Min("price"),
+		}.Build()
+		assert.NoError(t, err)
+
+		got, err := json.Marshal(aggs)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"total": {"count": "*"},
+			"byDay": {"dateHistogram": {"column": "createdAt", "interval": "1d", "timezone": "+01:00", "aggs": {
+				"revenue": {"sum": {"column": "amount"}},
+				"byStatus": {"topValues": {"column": "status", "size": 3, "aggs": {
+					"p": {"percentiles": {"column": "amount", "percentiles": [50, 99]}}
+				}}}
+			}}},
+			"prices": {"numericHistogram": {"column": "price", "interval": 10, "offset": 5, "aggs": {
+				"max": {"max": {"column": "price"}}
+			}}},
+			"users": {"uniqueCount": {"column": "user", "precisionThreshold": 100}},
+			"avg": {"average": {"column": "price"}},
+			"min": {"min": {"column": "price"}}
+		}`, string(got))
+	})
+
+	t.Run("should build a calendar interval", func(t *testing.T) {
+		aggs, err := agg.Map{
+			"byMonth": agg.DateHistogram("createdAt").CalendarInterval(xata.DateHistogramAggCalendarIntervalMonth),
+		}.Build()
+		assert.NoError(t, err)
+
+		got, err := json.Marshal(aggs)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"byMonth": {"dateHistogram": {"column": "createdAt", "calendarInterval": "month"}}}`, string(got))
+	})
+
+	invalid := map[string]agg.Map{
+		"empty column":              {"a": agg.Sum("")},
+		"empty name":                {"": agg.Count()},
+		"nil aggregation":           {"a": nil},
+		"missing interval":          {"a": agg.DateHistogram("createdAt")},
+		"both intervals":            {"a": agg.DateHistogram("createdAt").Interval("1d").CalendarInterval(xata.DateHistogramAggCalendarIntervalDay)},
+		"malformed interval":        {"a": agg.DateHistogram("createdAt").Interval("one day")},
+		"malformed timezone":        {"a": agg.DateHistogram("createdAt").Interval("1d").Timezone("CET")},
+		"invalid size":              {"a": agg.TopValues("status").Size(0)},
+		"invalid histogram":         {"a": agg.NumericHistogram("price", 0)},
+		"missing percentiles":       {"a": agg.Percentiles("price")},
+		"out of range percentile":   {"a": agg.Percentiles("price", 101)},
+		"negative precision":        {"a": agg.UniqueCount("user").PrecisionThreshold(-1)},
+		"invalid sub-aggregation":   {"a": agg.TopValues("status").Sub("b", agg.Average(""))},
+		"invalid nested sub-bucket": {"a": agg.NumericHistogram("price", 1).Sub("b", agg.TopValues("x").Sub("c", nil))},
+	}
+
+	for name, aggs := range invalid {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			got, err := aggs.Build()
+			assert.Error(t, err)
+			assert.Nil(t, got)
+		})
+	}
+
+	t.Run("should prefix errors with the aggregation path", func(t *testing.T) {
+		_, err := agg.Map{"a": agg.TopValues("status").Sub("b", agg.Average(""))}.Build()
+		assert.EqualError(t, err, "a: b: column cannot be empty")
+	})
+}
@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+const (
+	aggBucketKey   = "$key"
+	aggBucketCount = "$count"
+	aggValues      = "values"
+)
+
+// AggregateTableResponse is the response of AggregateResults. On top of the generated response, it
+// holds the raw results of the aggregations, which include the sub-aggregations of the buckets,
+// and offers typed accessors to them.
+type AggregateTableResponse struct {
+	xatagenworkspace.AggregateTableResponse
+	AggResults
+}
+
+// AggResults holds the results of aggregations by name.
+type AggResults map[string]json.RawMessage
+
+// AggBucket is a bucket of a date histogram, numeric histogram or top values aggregation.
+type AggBucket struct {
+	// Key is a string for date histograms and string columns, a float64 for numeric columns and a
+	// bool for boolean columns.
+	Key   any
+	Count int
+	// Aggs holds the results of the sub-aggregations of the bucket.
+	Aggs AggResults
+}
+
+// KeyString returns the key of the bucket formatted as a string.
+func (b AggBucket) KeyString() string {
+	switch k := b.Key.(type) {
+	case string:
+		return k
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(k)
+	}
+}
+
+// KeyTime returns the key of a date histogram bucket.
+func (b AggBucket) KeyTime() (time.Time, error) {
+	k, ok := b.Key.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("bucket key %v is not a date", b.Key)
+	}
+	return time.Parse(time.RFC3339, k)
+}
+
+func (a AggResults) get(name string) (json.RawMessage, error) {
+	raw, ok := a[name]
+	if !ok {
+		return nil, fmt.Errorf("aggregation %q not found", name)
+	}
+	return raw, nil
+}
+
+// Count returns the result of a count or unique count aggregation.
+func (a AggResults) Count(name string) (int, error) {
+	raw, err := a.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	var count float64
+	if err := json.Unmarshal(raw, &count); err != nil {
+		return 0, fmt.Errorf("aggregation %q is not a count: %w", name, err)
+	}
+	return int(count), nil
+}
+
+// Value returns the result of a sum, min, max or average aggregation.
+// It is nil if there were no values to aggregate.
+func (a AggResults) Value(name string) (*float64, error) {
+	raw, err := a.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var value *float64
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("aggregation %q is not a value: %w", name, err)
+	}
+	return value, nil
+}
+
+// Percentiles returns the result of a percentiles aggregation, keyed by percentile (e.g. "50.0").
+func (a AggResults) Percentiles(name string) (map[string]float64, error) {
+	raw, err := a.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Values map[string]float64 `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("aggregation %q is not a percentiles aggregation: %w", name, err)
+	}
+	return res.Values, nil
+}
+
+// Buckets returns the buckets of a date histogram, numeric histogram or top values aggregation.
+func (a AggResults) Buckets(name string) ([]AggBucket, error) {
+	raw, err := a.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Values []map[string]json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("aggregation %q is not a bucket aggregation: %w", name, err)
+	}
+
+	buckets := make([]AggBucket, 0, len(res.Values))
+	for _, v := range res.Values {
+		bucket := AggBucket{Aggs: AggResults{}}
+		for k, raw := range v {
+			switch k {
+			case aggBucketKey:
+				if err := json.Unmarshal(raw, &bucket.Key); err != nil {
+					return nil, err
+				}
+			case aggBucketCount:
+				if err := json.Unmarshal(raw, &bucket.Count); err != nil {
+					return nil, err
+				}
+			default:
+				bucket.Aggs[k] = raw
+			}
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, nil
+}
+
+// Decode decodes the results into v using its `json` tags. The `values` wrappers of bucket and
+// percentiles aggregations are removed, so buckets decode into slices of structs with fields
+// tagged `$key`, `$count` and the names of the sub-aggregations.
+//
+//	var out struct {
+//		Total int `json:"total"`
+//		ByDay []struct {
+//			Day     time.Time `json:"$key"`
+//			Count   int       `json:"$count"`
+//			Revenue float64   `json:"revenue"`
+//		} `json:"byDay"`
+//		P map[string]float64 `json:"p"`
+//	}
+//	err := resp.Decode(&out)
+//
+// The results of other shapes are decoded as they are.
+func (a AggResults) Decode(v any) error {
+	normalized := make(map[string]any, len(a))
+	for k, raw := range a {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		normalized[k] = unwrapAggResult(value)
+	}
+
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// unwrapAggResult replaces the `{"values": ...}` object of a bucket or percentiles aggregation
+// result with its content, and does the same for the sub-aggregations of the buckets. The
+// results of other shapes are left as they are.
+func unwrapAggResult(value any) any {
+	wrapper, ok := value.(map[string]any)
+	if !ok || len(wrapper) != 1 {
+		return value
+	}
+
+	switch inner := wrapper[aggValues].(type) {
+	case []any:
+		// The buckets of a date histogram, numeric histogram or top values aggregation.
+		for _, item := range inner {
+			bucket, ok := item.(map[string]any)
+			if !ok {
+				return value
+			}
+			_, hasKey := bucket[aggBucketKey]
+			_, hasCount := bucket[aggBucketCount]
+			if !hasKey || !hasCount {
+				return value
+			}
+		}
+		for _, item := range inner {
+			bucket := item.(map[string]any)
+			for k, sub := range bucket {
+				if k != aggBucketKey && k != aggBucketCount {
+					bucket[k] = unwrapAggResult(sub)
+				}
+			}
+		}
+		return inner
+	case map[string]any:
+		// The values of a percentiles aggregation, by percentile.
+		for _, percentile := range inner {
+			if _, ok := percentile.(float64); !ok && percentile != nil {
+				return value
+			}
+		}
+		return inner
+	default:
+		return value
+	}
+}
+
+func newAggregateTableResponse(gen *xatagenworkspace.AggregateTableResponse, raw []byte) (*AggregateTableResponse, error) {
+	var res struct {
+		Aggs AggResults `json:"aggs"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AggregateTableResponse{
+		AggregateTableResponse: *gen,
+		AggResults:             res.Aggs,
+	}, nil
+}
+
+// recordedResponseKey is the context key of the recordedResponse of a request.
+type recordedResponseKey struct{}
+
+// recordedResponse is a copy of the body and status code of a response, for the responses the
+// generated types don't fully represent.
+type recordedResponse struct {
+	body       []byte
+	statusCode int
+}
+
+// withRecordedResponse returns a context whose requests' responses are copied to rec by
+// responseRecorder.
+func withRecordedResponse(ctx context.Context, rec *recordedResponse) context.Context {
+	return context.WithValue(ctx, recordedResponseKey{}, rec)
+}
+
+// responseRecorder is an httpClient copying the responses of the requests whose context has a
+// recordedResponse, see withRecordedResponse.
+type responseRecorder struct {
+	next httpClient
+}
+
+func (r responseRecorder) Do(req *http.Request) (*http.Response, error) {
+	rec, ok := req.Context().Value(recordedResponseKey{}).(*recordedResponse)
+	if !ok {
+		return r.next.Do(req)
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rec.body = body
+	rec.statusCode = resp.StatusCode
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+func TestAggregateTableResponse(t *testing.T) {
+	raw := map[string]any{
+		"aggs": map[string]any{
+			"total":   42,
+			"revenue": 12.5,
+			"empty":   nil,
+			"p":       map[string]any{"values": map[string]float64{"50.0": 3, "99.0": 9}},
+			"byDay": map[string]any{
+				"values": []map[string]any{
+					{"$key": "2023-01-01T00:00:00Z", "$count": 2, "revenue": 5.5, "byStatus": map[string]any{
+						"values": []map[string]any{{"$key": "paid", "$count": 1}},
+					}},
+					{"$key": "2023-01-02T00:00:00Z", "$count": 1, "revenue": 7},
+				},
+			},
+		},
+	}
+
+	testSrv := testService(t, http.MethodPost, "/db", http.StatusOK, false, raw)
+	defer testSrv.Close()
+
+	cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	resp, err := xata.AggregateResults(context.TODO(), cli, xata.AggregateTableRequest{
+		BranchRequestOptional: xata.BranchRequestOptional{DatabaseName: xata.String("my-db")},
+		TableName:             "my-table",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *resp.Aggs, 5)
+
+	t.Run("should read counts and values", func(t *testing.T) {
+		total, err := resp.Count("total")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, total)
+
+		revenue, err := resp.Value("revenue")
+		assert.NoError(t, err)
+		assert.Equal(t, 12.5, *revenue)
+
+		empty, err := resp.Value("empty")
+		assert.NoError(t, err)
+		assert.Nil(t, empty)
+
+		_, err = resp.Count("missing")
+		assert.Error(t, err)
+		_, err = resp.Count("byDay")
+		assert.Error(t, err)
+	})
+
+	t.Run("should read percentiles", func(t *testing.T) {
+		p, err := resp.Percentiles("p")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"50.0": 3, "99.0": 9}, p)
+	})
+
+	t.Run("should read buckets with sub-aggregations", func(t *testing.T) {
+		buckets, err := resp.Buckets("byDay")
+		assert.NoError(t, err)
+		assert.Len(t, buckets, 2)
+
+		day, err := buckets[0].KeyTime()
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), day)
+		assert.Equal(t, 2, buckets[0].Count)
+
+		revenue, err := buckets[0].Aggs.Value("revenue")
+		assert.NoError(t, err)
+		assert.Equal(t, 5.5, *revenue)
+
+		byStatus, err := buckets[0].Aggs.Buckets("byStatus")
+		assert.NoError(t, err)
+		assert.Equal(t, "paid", byStatus[0].KeyString())
+		assert.Equal(t, 1, byStatus[0].Count)
+	})
+
+	t.Run("should decode into a struct", func(t *testing.T) {
+		var out struct {
+			Total int `json:"total"`
+			ByDay []struct {
+				Day      time.Time `json:"$key"`
+				Count    int       `json:"$count"`
+				Revenue  float64   `json:"revenue"`
+				ByStatus []struct {
+					Status string `json:"$key"`
+					Count  int    `json:"$count"`
+				} `json:"byStatus"`
+			} `json:"byDay"`
+			P map[string]float64 `json:"p"`
+		}
+
+		assert.NoError(t, resp.Decode(&out))
+		assert.Equal(t, 42, out.Total)
+		assert.Len(t, out.ByDay, 2)
+		assert.Equal(t, 7.0, out.ByDay[1].Revenue)
+		assert.Equal(t, "paid", out.ByDay[0].ByStatus[0].Status)
+		assert.Equal(t, 9.0, out.P["99.0"])
+	})
+
+	t.Run("should only unwrap the values of buckets and percentiles", func(t *testing.T) {
+		results := xata.AggResults{
+			"labels": json.RawMessage(`{"values":{"name":"kept"}}`),
+			"items":  json.RawMessage(`{"values":[{"name":"kept"}]}`),
+		}
+
+		var out struct {
+			Labels struct {
+				Values map[string]string `json:"values"`
+			} `json:"labels"`
+			Items struct {
+				Values []map[string]string `json:"values"`
+			} `json:"items"`
+		}
+
+		assert.NoError(t, results.Decode(&out))
+		assert.Equal(t, "kept", out.Labels.Values["name"])
+		assert.Equal(t, "kept", out.Items.Values[0]["name"])
+	})
+	t.Run("should read the generated response of other clients", func(t *testing.T) {
+		resp, err := xata.AggregateResults(context.TODO(), aggregateOnlyClient{}, xata.AggregateTableRequest{TableName: "my-table"})
+		assert.NoError(t, err)
+
+		total, err := resp.Count("total")
+		assert.NoError(t, err)
+		assert.Equal(t, 42, total)
+	})
+}
+
+// aggregateOnlyClient is a SearchAndFilterClient implemented outside of the package, which only
+// answers Aggregate.
+type aggregateOnlyClient struct {
+	xata.SearchAndFilterClient
+}
+
+func (aggregateOnlyClient) Aggregate(context.Context, xata.AggregateTableRequest) (*xatagenworkspace.AggregateTableResponse, error) {
+	return &xatagenworkspace.AggregateTableResponse{Aggs: &map[string]*xatagenworkspace.AggResponse{
+		"total": xatagenworkspace.NewAggResponseFromDoubleOptional(xata.Float64(42)),
+	}}, nil
+}
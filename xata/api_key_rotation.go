@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+)
+
+// APIKeySink stores a newly created API key, e.g. in a file or a secret store.
+type APIKeySink func(ctx context.Context, key string) error
+
+// FileAPIKeySink writes the key alone to a file, readable only by its owner.
+func FileAPIKeySink(path string) APIKeySink {
+	return func(_ context.Context, key string) error {
+		return writeFileAtomic(path, []byte(key+"\n"))
+	}
+}
+
+// EnvFileAPIKeySink sets the key as the value of XATA_API_KEY in an env file, keeping the
+// other lines of the file as they are.
+func EnvFileAPIKeySink(path string) APIKeySink {
+	return func(_ context.Context, key string) error {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		entry := fmt.Sprintf("%s=%s", EnvXataAPIKey, key)
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		if len(content) == 0 {
+			lines = nil
+		}
+
+		found := false
+		for i, line := range lines {
+			trimmed := strings.TrimPrefix(strings.TrimSpace(line), "export ")
+			if strings.HasPrefix(trimmed, EnvXataAPIKey+"=") {
+				lines[i] = entry
+				found = true
+			}
+		}
+		if !found {
+			lines = append(lines, entry)
+		}
+
+		return writeFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"))
+	}
+}
+
+// writeFileAtomic replaces the content of a file through a temporary file, so that readers
+// never see a partially written key.
+func writeFileAtomic(path string, content []byte) error {
+	mode := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+type RotateAPIKeyRequest struct {
+	// OldKeyName is the name of the key being replaced. It must exist.
+	OldKeyName string
+	// NewKeyName defaults to the old name suffixed with the current UTC time.
+	NewKeyName string
+	// Sink stores the new key once verified, before the old one is deleted.
+	Sink APIKeySink
+	// ClientOptions configure the client verifying the new key, e.g. WithBaseURL.
+	// The API key of the options is replaced by the new key.
+	ClientOptions []ClientOption
+}
+
+// RotateAPIKey replaces an API key: it creates a new key, verifies that it authenticates, hands
+// it to the sink, and only then deletes the old key.
+// If verifying or storing the new key fails, the new key is deleted and the old one is kept.
+func RotateAPIKey(ctx context.Context, keys APIKeysClient, request RotateAPIKeyRequest) (*xatagencore.CreateUserApiKeyResponse, error) {
+	if request.OldKeyName == "" {
+		return nil, fmt.Errorf("old key name cannot be empty")
+	}
+	if request.Sink == nil {
+		return nil, fmt.Errorf("sink cannot be nil")
+	}
+
+	newKeyName := request.NewKeyName
+	if newKeyName == "" {
+		newKeyName = fmt.Sprintf("%s-%s", request.OldKeyName, time.Now().UTC().Format("20060102150405"))
+	}
+	if newKeyName == request.OldKeyName {
+		return nil, fmt.Errorf("new key name must differ from the old one")
+	}
+
+	existing, err := keys.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, k := range existing.Keys {
+		switch k.Name {
+		case request.OldKeyName:
+			found = true
+		case newKeyName:
+			return nil, fmt.Errorf("key %q already exists", newKeyName)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("key %q not found", request.OldKeyName)
+	}
+
+	created, err := keys.Create(ctx, newKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create key %q: %w", newKeyName, err)
+	}
+
+	abort := func(err error) (*xatagencore.CreateUserApiKeyResponse, error) {
+		if delErr := keys.Delete(ctx, newKeyName); delErr != nil {
+			return nil, fmt.Errorf("%w (and unable to delete the new key %q: %v)", err, newKeyName, delErr)
+		}
+		return nil, err
+	}
+
+	// Not appended to request.ClientOptions, which may share its backing array with the caller.
+	users, err := NewUsersClient(append(append([]ClientOption{}, request.ClientOptions...), WithAPIKey(created.Key))...)
+	if err != nil {
+		return abort(err)
+	}
+	if _, err := users.Get(ctx); err != nil {
+		return abort(fmt.Errorf("unable to verify the new key: %w", err))
+	}
+
+	if err := request.Sink(ctx, created.Key); err != nil {
+		return abort(fmt.Errorf("unable to store the new key: %w", err))
+	}
+
+	if err := keys.Delete(ctx, request.OldKeyName); err != nil {
+		return created, fmt.Errorf("new key %q is in use but the old key %q couldn't be deleted: %w", newKeyName, request.OldKeyName, err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// keysService keeps a set of API keys and only authenticates the user endpoint with one of
+// them. Keys are named after their value.
+type keysService struct {
+	t        *testing.T
+	keys     map[string]bool
+	rejectID string
+
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (k *keysService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	name := strings.TrimPrefix(r.URL.Path, "/user/keys/")
+
+	switch {
+	case r.URL.Path == "/user":
+		if !k.keys[bearer] || bearer == k.rejectID {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid API key"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "user-id"})
+	case r.URL.Path == "/user/keys":
+		var keys []map[string]string
+		for name := range k.keys {
+			keys = append(keys, map[string]string{"name": name, "createdAt": "2024-01-01T00:00:00Z"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	case r.Method == http.MethodPost:
+		k.keys[name] = true
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"name": name, "key": name, "createdAt": "2024-01-01T00:00:00Z"})
+	case r.Method == http.MethodDelete:
+		delete(k.keys, name)
+		k.deleted = append(k.deleted, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		// Not t.Fatal, the handler doesn't run in the test goroutine.
+		k.t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_RotateAPIKey(t *testing.T) {
+	newClient := func(t *testing.T, svc *keysService) (xata.APIKeysClient, []xata.ClientOption) {
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		opts := []xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("old")}
+		cli, err := xata.NewAPIKeysClient(opts...)
+		assert.NoError(t, err)
+		return cli, opts
+	}
+
+	t.Run("should replace the key", func(t *testing.T) {
+		svc := &keysService{t: t, keys: map[string]bool{"old": true}}
+		keys, opts := newClient(t, svc)
+
+		// The spare capacity would be overwritten by an append to the options.
+		opts = append(make([]xata.ClientOption, 0, len(opts)+1), opts...)
+
+		var stored string
+		got, err := xata.RotateAPIKey(context.TODO(), keys, xata.RotateAPIKeyRequest{
+			OldKeyName:    "old",
+			NewKeyName:    "new",
+			Sink:          func(_ context.Context, key string) error { stored = key; return nil },
+			ClientOptions: opts,
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, opts[:len(opts)+1][len(opts)])
+		assert.Equal(t, "new", got.Key)
+		assert.Equal(t, "new", stored)
+		assert.Equal(t, map[string]bool{"new": true}, svc.keys)
+	})
+
+	t.Run("should keep the old key when the sink fails", func(t *testing.T) {
+		svc := &keysService{t: t, keys: map[string]bool{"old": true}}
+		keys, opts := newClient(t, svc)
+
+		_, err := xata.RotateAPIKey(context.TODO(), keys, xata.RotateAPIKeyRequest{
+			OldKeyName:    "old",
+			NewKeyName:    "new",
+			Sink:          func(context.Context, string) error { return errors.New("store unavailable") },
+			ClientOptions: opts,
+		})
+		assert.ErrorContains(t, err, "store unavailable")
+		assert.Equal(t, map[string]bool{"old": true}, svc.keys)
+		assert.Equal(t, []string{"new"}, svc.deleted)
+	})
+
+	t.Run("should keep the old key when the new one doesn't authenticate", func(t *testing.T) {
+		svc := &keysService{t: t, keys: map[string]bool{"old": true}, rejectID: "new"}
+		keys, opts := newClient(t, svc)
+
+		stored := false
+		_, err := xata.RotateAPIKey(context.TODO(), keys, xata.RotateAPIKeyRequest{
+			OldKeyName:    "old",
+			NewKeyName:    "new",
+			Sink:          func(context.Context, string) error { stored = true; return nil },
+			ClientOptions: opts,
+		})
+		assert.ErrorContains(t, err, "unable to verify the new key")
+		assert.False(t, stored)
+		assert.Equal(t, map[string]bool{"old": true}, svc.keys)
+	})
+
+	t.Run("should validate the request", func(t *testing.T) {
+		svc := &keysService{t: t, keys: map[string]bool{"old": true, "taken": true}}
+		keys, _ := newClient(t, svc)
+		sink := func(context.Context, string) error { return nil }
+
+		for _, request := range []xata.RotateAPIKeyRequest{
+			{NewKeyName: "new", Sink: sink},
+			{OldKeyName: "old", NewKeyName: "new"},
+			{OldKeyName: "old", NewKeyName: "old", Sink: sink},
+			{OldKeyName: "old", NewKeyName: "taken", Sink: sink},
+			{OldKeyName: "missing", NewKeyName: "new", Sink: sink},
+		} {
+			_, err := xata.RotateAPIKey(context.TODO(), keys, request)
+			assert.Error(t, err)
+		}
+		assert.Len(t, svc.keys, 2)
+	})
+}
+
+func TestEnvFileAPIKeySink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(path, []byte("# comment\nXATA_BRANCH=main\nXATA_API_KEY=old\n"), 0o600))
+
+	assert.NoError(t, xata.EnvFileAPIKeySink(path)(context.TODO(), "new"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "# comment\nXATA_BRANCH=main\nXATA_API_KEY=new\n", string(content))
+
+	missing := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, xata.EnvFileAPIKeySink(missing)(context.TODO(), "new"))
+	content, err = os.ReadFile(missing)
+	assert.NoError(t, err)
+	assert.Equal(t, "XATA_API_KEY=new\n", string(content))
+}
+
+func TestFileAPIKeySink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	assert.NoError(t, xata.FileAPIKeySink(path)(context.TODO(), "new"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new\n", string(content))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
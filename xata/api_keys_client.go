@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+type APIKeysClient interface {
+	List(ctx context.Context) (*xatagencore.GetUserApiKeysResponse, error)
+	Create(ctx context.Context, name string) (*xatagencore.CreateUserApiKeyResponse, error)
+	Delete(ctx context.Context, name string) error
+}
+
+type apiKeysCli struct {
+	generated xatagencore.AuthenticationClient
+}
+
+// List retrieves the names and creation dates of the API keys of the user.
+// https://xata.io/docs/api-reference/user/keys#get-the-list-of-user-api-keys
+func (a apiKeysCli) List(ctx context.Context) (*xatagencore.GetUserApiKeysResponse, error) {
+	return a.generated.GetUserApiKeys(ctx)
+}
+
+// Create creates a new API key for the user. The key is only returned by this call.
+// https://xata.io/docs/api-reference/user/keys/key_name#create-and-return-new-api-key
+func (a apiKeysCli) Create(ctx context.Context, name string) (*xatagencore.CreateUserApiKeyResponse, error) {
+	if name == "" {
+		return nil, fmt.Errorf("key name cannot be empty")
+	}
+
+	return a.generated.CreateUserApiKey(ctx, name)
+}
+
+// Delete deletes an API key of the user.
+// https://xata.io/docs/api-reference/user/keys/key_name#delete-an-existing-api-key
+func (a apiKeysCli) Delete(ctx context.Context, name string) error {
+	if name == "" {
+		return fmt.Errorf("key name cannot be empty")
+	}
+
+	return a.generated.DeleteUserApiKey(ctx, name)
+}
+
+// NewAPIKeysClient constructs a client for managing the API keys of the user.
+func NewAPIKeysClient(opts ...ClientOption) (APIKeysClient, error) {
+	cliOpts, err := consolidateClientOptionsForCore(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeysCli{
+		generated: xatagencore.NewAuthenticationClient(
+			func(options *xatagenclient.ClientOptions) {
+				options.HTTPClient = cliOpts.HTTPClient
+				options.BaseURL = cliOpts.BaseURL
+				options.Bearer = cliOpts.Bearer
+			}),
+	}, nil
+}
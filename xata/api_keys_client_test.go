@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+	xatagen "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+func TestNewAPIKeysClient(t *testing.T) {
+	t.Run("should construct a new API keys client", func(t *testing.T) {
+		got, err := xata.NewAPIKeysClient(xata.WithAPIKey("my-api-token"))
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+}
+
+func Test_apiKeysClient_List(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagen.GetUserApiKeysResponse
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should list the API keys",
+			want: &xatagen.GetUserApiKeysResponse{
+				Keys: []*xatagen.GetUserApiKeysResponseKeysItem{
+					{Name: "ci", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, eTC := range errTestCasesCore {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodGet, "/user/keys", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewAPIKeysClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+			assert.NoError(err)
+
+			got, err := cli.List(context.TODO())
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_apiKeysClient_CreateDelete(t *testing.T) {
+	t.Run("should create an API key", func(t *testing.T) {
+		want := &xatagen.CreateUserApiKeyResponse{Name: "ci", Key: "xau_secret", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		testSrv := testService(t, http.MethodPost, "/user/keys/ci", http.StatusCreated, false, want)
+		defer testSrv.Close()
+
+		cli, err := xata.NewAPIKeysClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		got, err := cli.Create(context.TODO(), "ci")
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("should delete an API key", func(t *testing.T) {
+		testSrv := testService(t, http.MethodDelete, "/user/keys/ci", http.StatusNoContent, false, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewAPIKeysClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+		assert.NoError(t, cli.Delete(context.TODO(), "ci"))
+	})
+
+	t.Run("should validate the key name", func(t *testing.T) {
+		cli, err := xata.NewAPIKeysClient(xata.WithBaseURL("http://localhost"), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		_, err = cli.Create(context.TODO(), "")
+		assert.EqualError(t, err, "key name cannot be empty")
+		assert.EqualError(t, cli.Delete(context.TODO(), ""), "key name cannot be empty")
+	})
+}
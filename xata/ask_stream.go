@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go/core"
+)
+
+const contentTypeEventStream = "text/event-stream"
+
+var errAskStreamClosed = errors.New("ask stream closed")
+
+// askStreamMessage is the payload of a server-sent event of the ask endpoint, or the whole
+// answer when it isn't streamed.
+type askStreamMessage struct {
+	Type      string   `json:"type"`
+	Text      string   `json:"text"`
+	Answer    string   `json:"answer"`
+	SessionID string   `json:"sessionId"`
+	Records   []string `json:"records"`
+}
+
+// apiErrorBody is a generated error, decoded from the body of an error response.
+type apiErrorBody interface {
+	error
+	json.Unmarshaler
+}
+
+// askErrors are the generated errors of the ask endpoint, by status code.
+var askErrors = map[int]func(*xatagenclient.APIError) apiErrorBody{
+	http.StatusBadRequest: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagenworkspace.BadRequestError{APIError: e}
+	},
+	http.StatusUnauthorized: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagenworkspace.UnauthorizedError{APIError: e}
+	},
+	http.StatusNotFound: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagenworkspace.NotFoundError{APIError: e}
+	},
+	http.StatusTooManyRequests: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagenworkspace.TooManyRequestsError{APIError: e}
+	},
+	http.StatusServiceUnavailable: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagenworkspace.ServiceUnavailableError{APIError: e}
+	},
+}
+
+// decodeAskError decodes an error response of the ask endpoint into its generated error, as the
+// generated client does.
+func decodeAskError(statusCode int, body io.Reader) error {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	apiError := xatagenclient.NewAPIError(statusCode, errors.New(string(raw)))
+	newErr, ok := askErrors[statusCode]
+	if !ok {
+		return apiError
+	}
+
+	value := newErr(apiError)
+	if err := value.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+	return value
+}
+
+// AskTableStream iterates over the chunks of a streamed answer. It holds the connection until
+// it's closed.
+//
+//	stream, err := xata.AskStream(ctx, cli, request)
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//
+//	for stream.Next() {
+//		fmt.Print(stream.Chunk())
+//	}
+//	if err := stream.Err(); err != nil {
+//		return err
+//	}
+type AskTableStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	body   io.ReadCloser
+	reader *bufio.Reader
+	// eventStream tells whether the body is made of server-sent events, or is a single JSON
+	// document with the whole answer.
+	eventStream bool
+	// read is set once the JSON document of a non-streamed answer has been read.
+	read      bool
+	chunk     string
+	answer    strings.Builder
+	sessionID string
+	records   []string
+	err       error
+	// closed may be set by Close from another goroutine than the one calling Next.
+	closed atomic.Bool
+}
+
+func newAskTableStream(ctx context.Context, cancel context.CancelFunc, body io.ReadCloser, eventStream bool) *AskTableStream {
+	return &AskTableStream{
+		ctx:         ctx,
+		cancel:      cancel,
+		body:        body,
+		reader:      bufio.NewReader(body),
+		eventStream: eventStream,
+	}
+}
+
+// Next advances the stream to the next answer chunk. It returns false when the answer is
+// complete, the stream was closed or an error occurred.
+func (a *AskTableStream) Next() bool {
+	for a.err == nil {
+		data, err := a.nextEvent()
+		if err != nil {
+			switch {
+			case a.closed.Load():
+				a.err = errAskStreamClosed
+			case a.ctx.Err() != nil:
+				a.err = a.ctx.Err()
+			case !errors.Is(err, io.EOF):
+				a.err = err
+			}
+			return false
+		}
+
+		if data == "" || data == "[DONE]" {
+			continue
+		}
+
+		var msg askStreamMessage
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			a.err = fmt.Errorf("unable to decode ask stream event: %w", err)
+			return false
+		}
+
+		if msg.SessionID != "" {
+			a.sessionID = msg.SessionID
+		}
+		if len(msg.Records) > 0 {
+			a.records = append(a.records, msg.Records...)
+		}
+
+		chunk := msg.Text
+		if chunk == "" {
+			chunk = msg.Answer
+		}
+		if chunk != "" {
+			a.chunk = chunk
+			a.answer.WriteString(chunk)
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextEvent reads the data of the next server-sent event, or the whole body when the answer
+// isn't streamed.
+func (a *AskTableStream) nextEvent() (string, error) {
+	if !a.eventStream {
+		if a.read {
+			return "", io.EOF
+		}
+		a.read = true
+
+		data, err := io.ReadAll(a.reader)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	var data []string
+	for {
+		line, err := a.reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			if errors.Is(err, io.EOF) && len(data) > 0 {
+				return strings.Join(data, "\n"), nil
+			}
+			return "", err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				return strings.Join(data, "\n"), nil
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment, used as keep-alive
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+// Chunk returns the answer chunk read by the last call to Next.
+func (a *AskTableStream) Chunk() string {
+	return a.chunk
+}
+
+// Answer returns the answer received so far.
+func (a *AskTableStream) Answer() string {
+	return a.answer.String()
+}
+
+// SessionID returns the session ID of the chat, it's set once received from the stream.
+func (a *AskTableStream) SessionID() string {
+	return a.sessionID
+}
+
+// Records returns the IDs of the records the answer is based on.
+func (a *AskTableStream) Records() []string {
+	return a.records
+}
+
+// Err returns the first error that occurred while reading the stream.
+func (a *AskTableStream) Err() error {
+	return a.err
+}
+
+// Close stops reading the stream and releases the connection. It must be called once done
+// with the stream, also when the answer was read to the end. It may be called from another
+// goroutine than the one calling Next, e.g. to stop the answer from a UI.
+func (a *AskTableStream) Close() error {
+	a.closed.Store(true)
+	a.cancel()
+	return a.body.Close()
+}
+
+// AskStream asks your table a question and streams the answer as it is generated.
+// The stream is stopped when the context is cancelled, and must be closed once done with.
+// The clients other than those of NewSearchAndFilterClient answer with Ask, in a single chunk.
+// https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/ask#ask-your-table-a-question
+func AskStream(ctx context.Context, client SearchAndFilterClient, request AskTableRequest) (*AskTableStream, error) {
+	if s, ok := client.(searchAndFilterCli); ok {
+		return s.askStream(ctx, request)
+	}
+
+	resp, err := client.Ask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return newAskTableStream(ctx, cancel, io.NopCloser(bytes.NewReader(body)), false), nil
+}
+
+// askStream sends the ask request with the HTTP client directly, since the generated client
+// decodes the whole body before returning. The error responses are decoded as the generated
+// client does.
+func (s searchAndFilterCli) askStream(ctx context.Context, request AskTableRequest) (*AskTableStream, error) {
+	dbBranchName, err := s.dbBranchName(request.BranchRequestOptional)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(constructAskTableRequest(request.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	endpointURL := fmt.Sprintf(s.baseURL+"/"+"db/%v/tables/%v/ask", dbBranchName, request.TableName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", contentTypeEventStream)
+	if s.bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+s.bearer)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, decodeAskError(resp.StatusCode, resp.Body)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return newAskTableStream(ctx, cancel, resp.Body, mediaType == contentTypeEventStream), nil
+}
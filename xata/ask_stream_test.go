@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+func TestAskStream(t *testing.T) {
+	askRequest := xata.AskTableRequest{
+		BranchRequestOptional: xata.BranchRequestOptional{
+			DatabaseName: xata.String("some-db"),
+			BranchName:   xata.String("main"),
+		},
+		TableName: "some-table",
+		Payload:   xata.AskTableRequestPayload{Question: "what is xata?"},
+	}
+
+	t.Run("should stream the answer", func(t *testing.T) {
+		testSrv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(t, "text/event-stream", request.Header.Get("Accept"))
+			assert.Equal(t, "Bearer test-key", request.Header.Get("Authorization"))
+
+			writer.Header().Set("Content-Type", "text/event-stream")
+			writer.WriteHeader(http.StatusOK)
+			for _, event := range []string{
+				`{"type":"sessionId","sessionId":"session-1"}`,
+				`{"type":"records","records":["rec_1","rec_2"]}`,
+				`{"type":"answer","text":"Xata is "}`,
+				`{"type":"answer","text":"a database."}`,
+			} {
+				_, _ = fmt.Fprintf(writer, ": keep-alive\ndata: %s\n\n", event)
+				writer.(http.Flusher).Flush()
+			}
+		}))
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		stream, err := xata.AskStream(context.TODO(), cli, askRequest)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		var chunks []string
+		for stream.Next() {
+			chunks = append(chunks, stream.Chunk())
+		}
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, []string{"Xata is ", "a database."}, chunks)
+		assert.Equal(t, "Xata is a database.", stream.Answer())
+		assert.Equal(t, "session-1", stream.SessionID())
+		assert.Equal(t, []string{"rec_1", "rec_2"}, stream.Records())
+	})
+
+	t.Run("should return a non-streamed answer", func(t *testing.T) {
+		testSrv := testService(t, http.MethodPost, "/db", http.StatusOK, false, map[string]string{
+			"answer":    "Xata is a database.",
+			"sessionId": "session-1",
+		})
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		stream, err := xata.AskStream(context.TODO(), cli, askRequest)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		assert.True(t, stream.Next())
+		assert.False(t, stream.Next())
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, "Xata is a database.", stream.Answer())
+		assert.Equal(t, "session-1", stream.SessionID())
+	})
+
+	t.Run("should return a pretty-printed non-streamed answer", func(t *testing.T) {
+		testSrv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = fmt.Fprint(writer, "{\n  \"answer\": \"Xata is a database.\",\n  \"sessionId\": \"session-1\"\n}\n")
+		}))
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		stream, err := xata.AskStream(context.TODO(), cli, askRequest)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		assert.True(t, stream.Next())
+		assert.False(t, stream.Next())
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, "Xata is a database.", stream.Answer())
+		assert.Equal(t, "session-1", stream.SessionID())
+	})
+
+	t.Run("should answer with Ask for other clients", func(t *testing.T) {
+		stream, err := xata.AskStream(context.TODO(), askOnlyClient{}, askRequest)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		assert.True(t, stream.Next())
+		assert.Equal(t, "Xata is a database.", stream.Chunk())
+		assert.False(t, stream.Next())
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, "session-1", stream.SessionID())
+	})
+
+	t.Run("should return the API error", func(t *testing.T) {
+		testSrv := testService(t, http.MethodPost, "/db", http.StatusBadRequest, true, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		stream, err := xata.AskStream(context.TODO(), cli, askRequest)
+		assert.Nil(t, stream)
+		assert.ErrorContains(t, err, "400")
+
+		var badRequestErr *xatagenworkspace.BadRequestError
+		assert.True(t, errors.As(err, &badRequestErr))
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		testSrv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "text/event-stream")
+			writer.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(writer, "data: {\"text\":\"partial\"}\n\n")
+			writer.(http.Flusher).Flush()
+			<-request.Context().Done()
+		}))
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := xata.AskStream(ctx, cli, askRequest)
+		assert.NoError(t, err)
+		defer stream.Close()
+
+		assert.True(t, stream.Next())
+		cancel()
+		assert.False(t, stream.Next())
+		assert.ErrorIs(t, stream.Err(), context.Canceled)
+	})
+
+	t.Run("should stop when closed from another goroutine", func(t *testing.T) {
+		testSrv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "text/event-stream")
+			writer.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(writer, "data: {\"text\":\"partial\"}\n\n")
+			writer.(http.Flusher).Flush()
+			<-request.Context().Done()
+		}))
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		stream, err := xata.AskStream(context.TODO(), cli, askRequest)
+		assert.NoError(t, err)
+
+		assert.True(t, stream.Next())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = stream.Close()
+		}()
+		assert.False(t, stream.Next())
+		<-done
+		assert.EqualError(t, stream.Err(), "ask stream closed")
+	})
+}
+
+// askOnlyClient is a SearchAndFilterClient implemented outside of the package, which only answers
+// Ask.
+type askOnlyClient struct {
+	xata.SearchAndFilterClient
+}
+
+func (askOnlyClient) Ask(context.Context, xata.AskTableRequest) (*xatagenworkspace.AskTableResponse, error) {
+	return &xatagenworkspace.AskTableResponse{Answer: "Xata is a database.", SessionId: "session-1"}, nil
+}
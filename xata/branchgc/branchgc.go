@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package branchgc deletes stale Xata branches, e.g. the preview branches created per pull
+// request and never cleaned up.
+//
+// The branches are selected by age, by BranchMetadata labels or stage, and by whether the git
+// branch they were created for still exists. Collecting is done in two steps: Plan lists the
+// candidates without changing anything, and Apply deletes them once the plan is confirmed.
+package branchgc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// mainBranch is always protected.
+const mainBranch = "main"
+
+// Options select the branches to collect. A branch is a candidate when it matches every
+// criterion that is set, and at least one criterion has to be set.
+type Options struct {
+	DatabaseName string
+	// OlderThan selects the branches created more than the given duration ago.
+	OlderThan time.Duration
+	// Labels selects the branches having at least one of the labels in their metadata.
+	Labels []string
+	// Stages selects the branches whose metadata stage is one of the stages.
+	Stages []string
+	// LiveBranches are the git branches still existing. When non-nil, the branches whose git
+	// branch isn't in the list are selected. The git branch is the one of the branch metadata,
+	// or the Xata branch name when the metadata has none.
+	LiveBranches []string
+	// Protected branches are never selected, in addition to main.
+	Protected []string
+}
+
+func (o Options) hasCriteria() bool {
+	return o.OlderThan > 0 || len(o.Labels) > 0 || len(o.Stages) > 0 || o.LiveBranches != nil
+}
+
+// needsMetadata tells whether the details of the branches have to be fetched.
+func (o Options) needsMetadata() bool {
+	return len(o.Labels) > 0 || len(o.Stages) > 0 || o.LiveBranches != nil
+}
+
+// Candidate is a branch to delete.
+type Candidate struct {
+	BranchName string
+	CreatedAt  time.Time
+	// Reasons are the criteria the branch matched.
+	Reasons []string
+}
+
+// Plan lists the branches Apply deletes.
+type Plan struct {
+	DatabaseName string
+	Candidates   []Candidate
+	// Protected are the branches Apply refuses to delete, main and the protected branches of
+	// the options.
+	Protected []string
+}
+
+// String renders the plan, one branch per line.
+func (p *Plan) String() string {
+	if len(p.Candidates) == 0 {
+		return fmt.Sprintf("no stale branches in database %s\n", p.DatabaseName)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d stale branches in database %s:\n", len(p.Candidates), p.DatabaseName)
+	for _, c := range p.Candidates {
+		fmt.Fprintf(&b, "\t%s\tcreated %s\t%s\n", c.BranchName, c.CreatedAt.UTC().Format(time.RFC3339), strings.Join(c.Reasons, ", "))
+	}
+	return b.String()
+}
+
+type Collector struct {
+	branches xata.BranchClient
+}
+
+// New constructs a collector.
+func New(branches xata.BranchClient) *Collector {
+	return &Collector{
+		branches: branches,
+	}
+}
+
+// Plan lists the branches of the database matching the options. Nothing is deleted.
+func (c *Collector) Plan(ctx context.Context, opts Options) (*Plan, error) {
+	if opts.DatabaseName == "" {
+		return nil, fmt.Errorf("database name cannot be empty")
+	}
+	if !opts.hasCriteria() {
+		return nil, fmt.Errorf("at least one of age, labels, stages or live branches has to be set")
+	}
+
+	protected := toSet(opts.Protected)
+	protected[mainBranch] = true
+	live := toSet(opts.LiveBranches)
+
+	list, err := c.branches.List(ctx, opts.DatabaseName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the branches of %s: %w", opts.DatabaseName, err)
+	}
+
+	plan := &Plan{
+		DatabaseName: opts.DatabaseName,
+		Protected:    append([]string{mainBranch}, opts.Protected...),
+	}
+	for _, b := range list.Branches {
+		if b == nil || protected[b.Name] {
+			continue
+		}
+
+		var reasons []string
+		if opts.OlderThan > 0 {
+			if time.Since(b.CreatedAt) < opts.OlderThan {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("older than %s", opts.OlderThan))
+		}
+
+		if opts.needsMetadata() {
+			details, err := c.branches.GetDetails(ctx, xata.BranchRequest{DatabaseName: xata.String(opts.DatabaseName), BranchName: b.Name})
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the details of branch %s: %w", b.Name, err)
+			}
+
+			matched, metadataReasons := matchMetadata((*xata.BranchMetadataWS)(details.Metadata), b.Name, opts, live)
+			if !matched {
+				continue
+			}
+			reasons = append(reasons, metadataReasons...)
+		}
+
+		plan.Candidates = append(plan.Candidates, Candidate{
+			BranchName: b.Name,
+			CreatedAt:  b.CreatedAt,
+			Reasons:    reasons,
+		})
+	}
+
+	return plan, nil
+}
+
+// Apply deletes the branches of the plan. It returns the deleted branches, including when
+// deleting one of them failed.
+func (c *Collector) Apply(ctx context.Context, plan *Plan) ([]string, error) {
+	// Checked again in case the plan was built or changed by hand.
+	protected := toSet(plan.Protected)
+	protected[mainBranch] = true
+	for _, candidate := range plan.Candidates {
+		if protected[candidate.BranchName] {
+			return nil, fmt.Errorf("refusing to delete protected branch %s", candidate.BranchName)
+		}
+	}
+
+	var deleted []string
+	for _, candidate := range plan.Candidates {
+		if _, err := c.branches.Delete(ctx, xata.BranchRequest{
+			DatabaseName: xata.String(plan.DatabaseName),
+			BranchName:   candidate.BranchName,
+		}); err != nil {
+			return deleted, fmt.Errorf("unable to delete branch %s: %w", candidate.BranchName, err)
+		}
+		deleted = append(deleted, candidate.BranchName)
+	}
+
+	return deleted, nil
+}
+
+func matchMetadata(metadata *xata.BranchMetadataWS, branchName string, opts Options, live map[string]bool) (bool, []string) {
+	if metadata == nil {
+		metadata = &xata.BranchMetadataWS{}
+	}
+
+	var reasons []string
+
+	if len(opts.Labels) > 0 {
+		label, ok := firstLabel(metadata, opts.Labels)
+		if !ok {
+			return false, nil
+		}
+		reasons = append(reasons, fmt.Sprintf("label %s", label))
+	}
+
+	if len(opts.Stages) > 0 {
+		if metadata.Stage == nil || !toSet(opts.Stages)[*metadata.Stage] {
+			return false, nil
+		}
+		reasons = append(reasons, fmt.Sprintf("stage %s", *metadata.Stage))
+	}
+
+	if opts.LiveBranches != nil {
+		gitBranch := branchName
+		if metadata.Branch != nil && *metadata.Branch != "" {
+			gitBranch = *metadata.Branch
+		}
+		if live[gitBranch] {
+			return false, nil
+		}
+		reasons = append(reasons, fmt.Sprintf("git branch %s is gone", gitBranch))
+	}
+
+	return true, reasons
+}
+
+func firstLabel(metadata *xata.BranchMetadataWS, labels []string) (string, bool) {
+	if metadata.Labels == nil {
+		return "", false
+	}
+
+	wanted := toSet(labels)
+	for _, l := range *metadata.Labels {
+		if wanted[l] {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package branchgc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+	"github.com/xataio/xata-go/xata/branchgc"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+type fakeBranchClient struct {
+	xata.BranchClient
+	branches  []*xatagenworkspace.Branch
+	metadata  map[string]*xatagenworkspace.BranchMetadata
+	deleted   []string
+	deleteErr error
+}
+
+func (f *fakeBranchClient) List(_ context.Context, dbName string) (*xatagenworkspace.ListBranchesResponse, error) {
+	return &xatagenworkspace.ListBranchesResponse{DatabaseName: dbName, Branches: f.branches}, nil
+}
+
+func (f *fakeBranchClient) GetDetails(_ context.Context, request xata.BranchRequest) (*xatagenworkspace.DbBranch, error) {
+	return &xatagenworkspace.DbBranch{BranchName: request.BranchName, Metadata: f.metadata[request.BranchName]}, nil
+}
+
+func (f *fakeBranchClient) Delete(_ context.Context, request xata.BranchRequest) (*xatagenworkspace.DeleteBranchResponse, error) {
+	if f.deleteErr != nil {
+		return nil, f.deleteErr
+	}
+	f.deleted = append(f.deleted, request.BranchName)
+	return &xatagenworkspace.DeleteBranchResponse{}, nil
+}
+
+func newFakeBranchClient() *fakeBranchClient {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	return &fakeBranchClient{
+		branches: []*xatagenworkspace.Branch{
+			{Name: "main", CreatedAt: old},
+			{Name: "staging", CreatedAt: old},
+			{Name: "pr-1", CreatedAt: old},
+			{Name: "pr-2", CreatedAt: old},
+			{Name: "pr-3", CreatedAt: recent},
+			{Name: "experiment", CreatedAt: old},
+		},
+		metadata: map[string]*xatagenworkspace.BranchMetadata{
+			"main":    {Labels: &[]string{"preview"}},
+			"staging": {Stage: xata.String("preview"), Labels: &[]string{"preview"}},
+			"pr-1":    {Stage: xata.String("preview"), Labels: &[]string{"preview"}, Branch: xata.String("feature/one")},
+			"pr-2":    {Stage: xata.String("preview"), Labels: &[]string{"preview"}, Branch: xata.String("feature/two")},
+			"pr-3":    {Stage: xata.String("preview"), Labels: &[]string{"preview"}, Branch: xata.String("feature/three")},
+		},
+	}
+}
+
+func candidateNames(plan *branchgc.Plan) []string {
+	var names []string
+	for _, c := range plan.Candidates {
+		names = append(names, c.BranchName)
+	}
+	return names
+}
+
+func TestCollector_Plan(t *testing.T) {
+	tests := []struct {
+		name string
+		opts branchgc.Options
+		want []string
+	}{
+		{
+			name: "should select by age",
+			opts: branchgc.Options{OlderThan: 7 * 24 * time.Hour},
+			want: []string{"staging", "pr-1", "pr-2", "experiment"},
+		},
+		{
+			name: "should select by label",
+			opts: branchgc.Options{Labels: []string{"preview", "other"}},
+			want: []string{"staging", "pr-1", "pr-2", "pr-3"},
+		},
+		{
+			name: "should select by stage and protect the configured branches",
+			opts: branchgc.Options{Stages: []string{"preview"}, Protected: []string{"staging"}},
+			want: []string{"pr-1", "pr-2", "pr-3"},
+		},
+		{
+			name: "should select the branches whose git branch is gone",
+			opts: branchgc.Options{LiveBranches: []string{"feature/two", "experiment", "staging"}},
+			want: []string{"pr-1", "pr-3"},
+		},
+		{
+			name: "should combine the criteria",
+			opts: branchgc.Options{
+				OlderThan:    7 * 24 * time.Hour,
+				Labels:       []string{"preview"},
+				LiveBranches: []string{"feature/two", "staging"},
+			},
+			want: []string{"pr-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.DatabaseName = "db"
+			plan, err := branchgc.New(newFakeBranchClient()).Plan(context.TODO(), tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, candidateNames(plan))
+		})
+	}
+
+	t.Run("should explain the candidates", func(t *testing.T) {
+		plan, err := branchgc.New(newFakeBranchClient()).Plan(context.TODO(), branchgc.Options{
+			DatabaseName: "db",
+			OlderThan:    24 * time.Hour,
+			Stages:       []string{"preview"},
+			LiveBranches: []string{},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"older than 24h0m0s", "stage preview", "git branch feature/one is gone"}, plan.Candidates[1].Reasons)
+		assert.Contains(t, plan.String(), "3 stale branches in database db:")
+		assert.Contains(t, plan.String(), "pr-2")
+	})
+
+	t.Run("should require a database and a criterion", func(t *testing.T) {
+		_, err := branchgc.New(newFakeBranchClient()).Plan(context.TODO(), branchgc.Options{Labels: []string{"preview"}})
+		assert.Error(t, err)
+
+		_, err = branchgc.New(newFakeBranchClient()).Plan(context.TODO(), branchgc.Options{DatabaseName: "db"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCollector_Apply(t *testing.T) {
+	t.Run("should delete the candidates", func(t *testing.T) {
+		branches := newFakeBranchClient()
+		collector := branchgc.New(branches)
+
+		plan, err := collector.Plan(context.TODO(), branchgc.Options{DatabaseName: "db", Labels: []string{"preview"}})
+		assert.NoError(t, err)
+
+		deleted, err := collector.Apply(context.TODO(), plan)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"staging", "pr-1", "pr-2", "pr-3"}, deleted)
+		assert.Equal(t, deleted, branches.deleted)
+	})
+
+	t.Run("should never delete main", func(t *testing.T) {
+		branches := newFakeBranchClient()
+
+		_, err := branchgc.New(branches).Apply(context.TODO(), &branchgc.Plan{
+			DatabaseName: "db",
+			Candidates:   []branchgc.Candidate{{BranchName: "main"}},
+		})
+		assert.Error(t, err)
+		assert.Empty(t, branches.deleted)
+	})
+
+	t.Run("should never delete the protected branches", func(t *testing.T) {
+		branches := newFakeBranchClient()
+		collector := branchgc.New(branches)
+
+		plan, err := collector.Plan(context.TODO(), branchgc.Options{DatabaseName: "db", Labels: []string{"preview"}, Protected: []string{"staging"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"main", "staging"}, plan.Protected)
+
+		plan.Candidates = append(plan.Candidates, branchgc.Candidate{BranchName: "staging"})
+		_, err = collector.Apply(context.TODO(), plan)
+		assert.ErrorContains(t, err, "refusing to delete protected branch staging")
+		assert.Empty(t, branches.deleted)
+	})
+
+	t.Run("should report the deletion errors", func(t *testing.T) {
+		branches := newFakeBranchClient()
+		branches.deleteErr = errors.New("server error")
+
+		deleted, err := branchgc.New(branches).Apply(context.TODO(), &branchgc.Plan{
+			DatabaseName: "db",
+			Candidates:   []branchgc.Candidate{{BranchName: "pr-1"}},
+		})
+		assert.ErrorContains(t, err, "unable to delete branch pr-1")
+		assert.Empty(t, deleted)
+	})
+}
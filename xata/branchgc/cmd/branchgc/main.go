@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Command branchgc deletes the stale branches of a Xata database.
+//
+// It prints the branches matching the flags, and deletes them after confirmation, e.g. to
+// delete the preview branches whose pull request branch is gone:
+//
+//	git ls-remote --heads origin | sed 's|.*refs/heads/||' > live.txt
+//	branchgc -db app -label preview -live live.txt
+//
+// The API key and the workspace are read from the environment, as for the xata clients.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/xataio/xata-go/xata"
+	"github.com/xataio/xata-go/xata/branchgc"
+)
+
+// listFlag is a flag that can be repeated.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var (
+		opts      branchgc.Options
+		labels    listFlag
+		stages    listFlag
+		protected listFlag
+		livePath  string
+		yes       bool
+	)
+
+	flag.StringVar(&opts.DatabaseName, "db", "", "database name")
+	flag.DurationVar(&opts.OlderThan, "older-than", 0, "select the branches older than the duration, e.g. 168h")
+	flag.Var(&labels, "label", "select the branches with the metadata label, can be repeated")
+	flag.Var(&stages, "stage", "select the branches with the metadata stage, can be repeated")
+	flag.StringVar(&livePath, "live", "", "file listing the live git branches, one per line, - for stdin; select the branches whose git branch isn't listed")
+	flag.Var(&protected, "protect", "never delete the branch, can be repeated (main is always protected)")
+	flag.BoolVar(&yes, "yes", false, "delete without asking for confirmation")
+	flag.Parse()
+
+	opts.Labels = labels
+	opts.Stages = stages
+	opts.Protected = protected
+
+	if livePath != "" {
+		live, err := readLiveBranches(livePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// An empty list, e.g. after a failed git ls-remote, would select every branch.
+		if len(live) == 0 {
+			log.Fatalf("no live branches in %s, refusing to select every branch", livePath)
+		}
+		opts.LiveBranches = live
+	}
+
+	ctx := context.Background()
+
+	branchCli, err := xata.NewBranchClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+	collector := branchgc.New(branchCli)
+
+	plan, err := collector.Plan(ctx, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(plan)
+	if len(plan.Candidates) == 0 {
+		return
+	}
+
+	if !yes {
+		if livePath == "-" {
+			log.Fatal("stdin is used for the live branches, confirm with -yes")
+		}
+		if !confirm(os.Stdin, os.Stdout, len(plan.Candidates)) {
+			fmt.Println("nothing deleted")
+			return
+		}
+	}
+
+	deleted, err := collector.Apply(ctx, plan)
+	for _, name := range deleted {
+		fmt.Printf("deleted %s\n", name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func readLiveBranches(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var live []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			live = append(live, name)
+		}
+	}
+	return live, scanner.Err()
+}
+
+func confirm(in io.Reader, out io.Writer, count int) bool {
+	fmt.Fprintf(out, "delete %d branches? [y/N] ", count)
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,459 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL      = time.Minute
+	defaultCacheCapacity = 1000
+)
+
+// CacheStore keeps the cached responses. Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the value of a key that hasn't expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the value of a key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// DeletePrefix deletes the keys starting with prefix.
+	DeletePrefix(prefix string)
+}
+
+// CacheOptions configure NewCache.
+type CacheOptions struct {
+	// Store keeps the responses, an in-memory LRU store of 1000 entries by default.
+	Store CacheStore
+	// TTL is how long a response is served from the cache, 1 minute by default.
+	TTL time.Duration
+}
+
+// CacheStats counts the lookups of a cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// Cache serves the records of RecordsClient.Get and the responses of
+// SearchAndFilterClient.Query from a store instead of the API, see WithCache.
+//
+// The entries of a table are dropped when a client using the cache writes to the table, with
+// Insert, Update, Upsert, Delete, BulkInsert, Transaction or any other write request. The
+// writes of other clients, or other processes, are only seen once the entries expire.
+//
+// The entries are kept per host and workspace. A hit is served without sending the request, so
+// its API key isn't checked: only share a cache between clients with the same access.
+type Cache struct {
+	store CacheStore
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	stats CacheStats
+	// generation changes on every invalidation, so that a response read before a write isn't
+	// stored after it.
+	generation uint64
+}
+
+// NewCache constructs a cache, to share between the clients with WithCache.
+func NewCache(opts CacheOptions) *Cache {
+	if opts.Store == nil {
+		opts.Store = NewLRUCacheStore(defaultCacheCapacity)
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultCacheTTL
+	}
+
+	return &Cache{
+		store: opts.Store,
+		ttl:   opts.TTL,
+	}
+}
+
+// WithCache serves the Get and Query requests of a workspace API client from the cache.
+// The clients sharing a cache see each other's writes, and are served each other's reads
+// without their API key being checked.
+func WithCache(cache *Cache) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		options.Cache = cache
+	}
+}
+
+// Stats returns the hits and misses since the cache was constructed.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+// InvalidateTable drops the entries of a table, e.g. after it was written by another process.
+func (c *Cache) InvalidateTable(dbName, branchName, tableName string) {
+	c.invalidate(tablePrefix(dbName+":"+branchName, tableName))
+}
+
+func (c *Cache) invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.store.DeletePrefix(prefix)
+}
+
+func (c *Cache) lookup(key string) ([]byte, uint64, bool) {
+	value, ok := c.store.Get(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return value, c.generation, ok
+}
+
+func (c *Cache) set(key string, value []byte, generation uint64) {
+	// Under the lock, so that an invalidation can't happen between the check and the store.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.store.Set(key, value, c.ttl)
+	}
+}
+
+// branchPrefix is the prefix of the keys of a branch, db_name:branch_name/.
+func branchPrefix(dbBranchName string) string {
+	return dbBranchName + "/"
+}
+
+// tablePrefix is the prefix of the keys of a table, db_name:branch_name/table_name/.
+func tablePrefix(dbBranchName, tableName string) string {
+	return branchPrefix(dbBranchName) + tableName + "/"
+}
+
+// cacheClient serves the cacheable requests from the cache, and invalidates it on writes.
+type cacheClient struct {
+	next  httpClient
+	cache *Cache
+	// workspaceID tells the workspaces apart when the host doesn't, e.g. with a router.
+	workspaceID string
+}
+
+func (c cacheClient) Do(req *http.Request) (*http.Response, error) {
+	route := parseCacheRoute(req)
+
+	switch {
+	case route.cacheable:
+		return c.cached(req, route)
+	case route.write:
+		prefixes := c.writtenPrefixes(req, route)
+		resp, err := c.next.Do(req)
+		// Also when the write failed, it may have been applied.
+		for _, prefix := range prefixes {
+			c.cache.invalidate(prefix)
+		}
+		return resp, err
+	default:
+		return c.next.Do(req)
+	}
+}
+
+func (c cacheClient) cached(req *http.Request, route cacheRoute) (*http.Response, error) {
+	key, err := cacheKey(req, route, c.workspaceID)
+	if err != nil {
+		return c.next.Do(req)
+	}
+
+	value, generation, ok := c.cache.lookup(key)
+	if ok {
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(value)),
+			ContentLength: int64(len(value)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	value, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.set(key, value, generation)
+	resp.Body = io.NopCloser(bytes.NewReader(value))
+	return resp, nil
+}
+
+// writtenPrefixes returns the prefixes of the entries a write request invalidates.
+func (c cacheClient) writtenPrefixes(req *http.Request, route cacheRoute) []string {
+	if route.tableName != "" {
+		return []string{tablePrefix(route.dbBranchName, route.tableName)}
+	}
+
+	if route.transaction {
+		if tables, ok := transactionTables(req); ok {
+			prefixes := make([]string, 0, len(tables))
+			for _, table := range tables {
+				prefixes = append(prefixes, tablePrefix(route.dbBranchName, table))
+			}
+			return prefixes
+		}
+	}
+
+	return []string{branchPrefix(route.dbBranchName)}
+}
+
+// cacheRoute classifies a workspace API request.
+type cacheRoute struct {
+	dbBranchName string
+	tableName    string
+	recordID     string
+	cacheable    bool
+	write        bool
+	transaction  bool
+}
+
+// readOnlyTableOperations are the POST requests on a table that don't write.
+var readOnlyTableOperations = map[string]bool{
+	"query":        true,
+	"search":       true,
+	"vectorSearch": true,
+	"aggregate":    true,
+	"summarize":    true,
+	"ask":          true,
+}
+
+func parseCacheRoute(req *http.Request) cacheRoute {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "db" {
+		return cacheRoute{}
+	}
+
+	route := cacheRoute{dbBranchName: segments[1]}
+	rest := segments[2:]
+
+	if len(rest) >= 2 && rest[0] == "tables" {
+		route.tableName = rest[1]
+		rest = rest[2:]
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		// GET /db/{db_branch_name}/tables/{table_name}/data/{record_id}
+		if req.Method == http.MethodGet && route.tableName != "" && len(rest) == 2 && rest[0] == "data" {
+			route.recordID = rest[1]
+			route.cacheable = true
+		}
+	case http.MethodPost:
+		switch {
+		case route.tableName != "" && len(rest) == 1 && rest[0] == "query":
+			route.cacheable = true
+		case route.tableName != "" && len(rest) >= 1 && readOnlyTableOperations[rest[0]]:
+		case route.tableName == "" && len(rest) == 1 && rest[0] == "search":
+		case route.tableName == "" && len(rest) == 1 && rest[0] == "transaction":
+			route.write = true
+			route.transaction = true
+		default:
+			route.write = true
+		}
+	default:
+		route.write = true
+	}
+
+	return route
+}
+
+// cacheKey derives the key of a cacheable request from the host, the workspace, the branch, the
+// table, the record ID, the columns and the canonical JSON of the query.
+// The host and the workspace are hashed rather than prefixed, so that the writes invalidate
+// the table in every workspace: more than needed, but never too little.
+func cacheKey(req *http.Request, route cacheRoute, workspaceID string) (string, error) {
+	// The columns are repeated or comma separated, in any order.
+	var columns []string
+	for _, value := range req.URL.Query()["columns"] {
+		columns = append(columns, strings.Split(value, ",")...)
+	}
+	sort.Strings(columns)
+
+	hash := sha256.New()
+	for _, part := range []string{req.URL.Host, workspaceID, route.recordID, strings.Join(columns, ",")} {
+		hash.Write([]byte(strconv.Itoa(len(part)) + ":" + part))
+	}
+
+	if route.recordID == "" {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return "", err
+		}
+		canonical, err := canonicalJSON(body)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(canonical)
+	}
+
+	return tablePrefix(route.dbBranchName, route.tableName) + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// canonicalJSON re-encodes a JSON document with sorted object keys.
+func canonicalJSON(data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// readRequestBody reads the body of a request, and puts it back to be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// transactionTables returns the tables of the operations of a transaction request.
+func transactionTables(req *http.Request) ([]string, bool) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, false
+	}
+
+	var transaction struct {
+		Operations []map[string]struct {
+			Table string `json:"table"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(body, &transaction); err != nil {
+		return nil, false
+	}
+
+	var tables []string
+	for _, operation := range transaction.Operations {
+		for _, op := range operation {
+			if op.Table == "" {
+				return nil, false
+			}
+			tables = append(tables, op.Table)
+		}
+	}
+	return tables, true
+}
+
+// lruCacheStore is an in-memory CacheStore evicting the least recently used entries.
+type lruCacheStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type lruCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRUCacheStore constructs an in-memory store keeping at most capacity entries.
+func NewLRUCacheStore(capacity int) CacheStore {
+	if capacity <= 0 {
+		capacity = defaultCacheCapacity
+	}
+
+	return &lruCacheStore{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (s *lruCacheStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.remove(element)
+		return nil, false
+	}
+
+	s.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (s *lruCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &lruCacheEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *lruCacheStore) DeletePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(element)
+		}
+	}
+}
+
+func (s *lruCacheStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*lruCacheEntry).key)
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// recordsService answers every read with the current version of the records, and counts
+// the requests per method and path.
+type recordsService struct {
+	mu       sync.Mutex
+	version  int
+	requests map[string]int
+}
+
+func (s *recordsService) count(request string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[request]
+}
+
+func (s *recordsService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[r.Method+" "+r.URL.Path]++
+
+	switch {
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "rec", "version": s.version})
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"records": []map[string]any{{"id": "rec", "version": s.version}}})
+	case strings.HasSuffix(r.URL.Path, "/transaction"):
+		s.version++
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": []any{}})
+	default:
+		s.version++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "rec"})
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	newClients := func(t *testing.T, opts xata.CacheOptions) (*recordsService, *xata.Cache, xata.RecordsClient, xata.SearchAndFilterClient) {
+		svc := &recordsService{requests: map[string]int{}}
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		cache := xata.NewCache(opts)
+		cliOpts := []xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithCache(cache)}
+
+		records, err := xata.NewRecordsClient(cliOpts...)
+		assert.NoError(t, err)
+		search, err := xata.NewSearchAndFilterClient(cliOpts...)
+		assert.NoError(t, err)
+
+		return svc, cache, records, search
+	}
+
+	recordRequest := func(table string) xata.RecordRequest {
+		return xata.RecordRequest{DatabaseName: xata.String("db"), BranchName: xata.String("main"), TableName: table}
+	}
+	get := func(cli xata.RecordsClient, table string, columns ...string) *xata.Record {
+		record, err := cli.Get(context.TODO(), xata.GetRecordRequest{RecordRequest: recordRequest(table), RecordID: "rec", Columns: columns})
+		assert.NoError(t, err)
+		return record
+	}
+	query := func(cli xata.SearchAndFilterClient, table string) {
+		request, err := xata.From(table).Database("db").Branch("main").Select("name", "version").Limit(10).Build()
+		assert.NoError(t, err)
+		_, err = cli.Query(context.TODO(), request)
+		assert.NoError(t, err)
+	}
+
+	t.Run("should serve the same reads from the cache", func(t *testing.T) {
+		svc, cache, records, search := newClients(t, xata.CacheOptions{})
+
+		first := get(records, "users", "name", "version")
+		second := get(records, "users", "version", "name")
+		assert.Equal(t, first, second)
+		assert.Equal(t, 1, svc.count("GET /db/db:main/tables/users/data/rec"))
+
+		get(records, "users", "name")
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+
+		query(search, "users")
+		query(search, "users")
+		assert.Equal(t, 1, svc.count("POST /db/db:main/tables/users/query"))
+
+		assert.Equal(t, xata.CacheStats{Hits: 2, Misses: 3}, cache.Stats())
+	})
+
+	t.Run("should invalidate the table on writes", func(t *testing.T) {
+		svc, _, records, search := newClients(t, xata.CacheOptions{})
+
+		get(records, "users")
+		get(records, "teams")
+		query(search, "users")
+
+		_, err := records.Insert(context.TODO(), xata.InsertRecordRequest{
+			RecordRequest: recordRequest("users"),
+			Body:          map[string]*xata.DataInputRecordValue{"name": xata.ValueFromString("jane")},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, float64(1), get(records, "users").Data["version"])
+		get(records, "teams")
+		query(search, "users")
+
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+		assert.Equal(t, 1, svc.count("GET /db/db:main/tables/teams/data/rec"))
+		assert.Equal(t, 2, svc.count("POST /db/db:main/tables/users/query"))
+	})
+
+	t.Run("should invalidate the tables of a transaction", func(t *testing.T) {
+		svc, _, records, _ := newClients(t, xata.CacheOptions{})
+
+		get(records, "users")
+		get(records, "teams")
+
+		_, err := records.Transaction(context.TODO(), xata.TransactionRequest{
+			RecordRequest: recordRequest(""),
+			Operations:    []xata.TransactionOperation{xata.NewDeleteTransaction(xata.TransactionDeleteOp{Table: "users", Id: "rec"})},
+		})
+		assert.NoError(t, err)
+
+		get(records, "users")
+		get(records, "teams")
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+		assert.Equal(t, 1, svc.count("GET /db/db:main/tables/teams/data/rec"))
+	})
+
+	t.Run("should expire the entries", func(t *testing.T) {
+		svc, _, records, _ := newClients(t, xata.CacheOptions{TTL: 20 * time.Millisecond})
+
+		get(records, "users")
+		time.Sleep(40 * time.Millisecond)
+		get(records, "users")
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+	})
+
+	t.Run("should invalidate a table on demand", func(t *testing.T) {
+		svc, cache, records, _ := newClients(t, xata.CacheOptions{})
+
+		get(records, "users")
+		cache.InvalidateTable("db", "main", "users")
+		get(records, "users")
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+	})
+
+	t.Run("should keep the hosts and workspaces apart", func(t *testing.T) {
+		cache := xata.NewCache(xata.CacheOptions{})
+		newClient := func(baseURL, workspaceID string) xata.RecordsClient {
+			cli, err := xata.NewRecordsClient(xata.WithBaseURL(baseURL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID(workspaceID), xata.WithCache(cache))
+			assert.NoError(t, err)
+			return cli
+		}
+
+		first := &recordsService{requests: map[string]int{}}
+		firstSrv := httptest.NewServer(first)
+		defer firstSrv.Close()
+		second := &recordsService{requests: map[string]int{}}
+		secondSrv := httptest.NewServer(second)
+		defer secondSrv.Close()
+
+		get(newClient(firstSrv.URL, "ws-a"), "users")
+		get(newClient(secondSrv.URL, "ws-a"), "users")
+		get(newClient(firstSrv.URL, "ws-b"), "users")
+		get(newClient(firstSrv.URL, "ws-a"), "users")
+
+		assert.Equal(t, 2, first.count("GET /db/db:main/tables/users/data/rec"))
+		assert.Equal(t, 1, second.count("GET /db/db:main/tables/users/data/rec"))
+	})
+
+	t.Run("should not store a read racing with a write", func(t *testing.T) {
+		store := &slowSetStore{CacheStore: xata.NewLRUCacheStore(10), setting: make(chan struct{}, 1)}
+		svc, _, records, _ := newClients(t, xata.CacheOptions{Store: store})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			get(records, "users")
+		}()
+
+		// The read is being stored when the write invalidates the table.
+		<-store.setting
+		_, err := records.Update(context.TODO(), xata.UpdateRecordRequest{
+			RecordRequest: recordRequest("users"),
+			RecordID:      "rec",
+			Body:          map[string]*xata.DataInputRecordValue{"name": xata.ValueFromString("jane")},
+		})
+		assert.NoError(t, err)
+		<-done
+
+		assert.Equal(t, float64(1), get(records, "users").Data["version"])
+		assert.Equal(t, 2, svc.count("GET /db/db:main/tables/users/data/rec"))
+	})
+}
+
+// slowSetStore signals the stores, and delays them.
+type slowSetStore struct {
+	xata.CacheStore
+	setting chan struct{}
+}
+
+func (s *slowSetStore) Set(key string, value []byte, ttl time.Duration) {
+	select {
+	case s.setting <- struct{}{}:
+	default:
+	}
+	time.Sleep(20 * time.Millisecond)
+	s.CacheStore.Set(key, value, ttl)
+}
+
+func TestNewLRUCacheStore(t *testing.T) {
+	store := xata.NewLRUCacheStore(2)
+
+	store.Set("db:main/users/a", []byte("a"), time.Minute)
+	store.Set("db:main/users/b", []byte("b"), time.Minute)
+	_, ok := store.Get("db:main/users/a")
+	assert.True(t, ok)
+
+	// b is the least recently used.
+	store.Set("db:main/teams/c", []byte("c"), time.Minute)
+	_, ok = store.Get("db:main/users/b")
+	assert.False(t, ok)
+
+	store.DeletePrefix("db:main/users/")
+	_, ok = store.Get("db:main/users/a")
+	assert.False(t, ok)
+	got, ok := store.Get("db:main/teams/c")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("c"), got)
+}
@@ -5,6 +5,7 @@ package xata
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // httpClient is an interface for a subset of the *http.Client.
@@ -20,6 +21,18 @@ type ClientOptions struct {
 	WorkspaceID string
 	Region      string
 	Branch      string
+	// DataPlaneDomain is the domain of the workspace API hosts, {workspace_id}.{region}.{domain}.
+	DataPlaneDomain string
+	// Credentials, when set, authenticates every request instead of Bearer.
+	Credentials CredentialsProvider
+	// Timeout is the deadline of every request, see WithDefaultTimeout.
+	Timeout time.Duration
+	// OperationTimeouts override Timeout for some operations, see WithOperationTimeout.
+	OperationTimeouts map[Operation]time.Duration
+	// Cache, when set, serves the Get and Query requests, see WithCache.
+	Cache *Cache
+	// Router, when set, sends the requests to the region of their database, see WithRouter.
+	Router *Router
 }
 
 func consolidateClientOptionsForCore(opts ...ClientOption) (*ClientOptions, error) {
@@ -33,11 +46,18 @@ func consolidateClientOptionsForCore(opts ...ClientOption) (*ClientOptions, erro
 		cliOpts.HTTPClient = http.DefaultClient
 	}
 
+	if cliOpts.Credentials != nil {
+		cliOpts.HTTPClient = credentialsClient{next: cliOpts.HTTPClient, provider: cliOpts.Credentials}
+	}
+
+	// Around the other clients, so that the deadline covers their retries.
+	cliOpts.HTTPClient = newTimeoutClient(cliOpts)
+
 	if cliOpts.BaseURL == "" {
 		cliOpts.BaseURL = fmt.Sprintf("https://%s", defaultControlPlaneDomain)
 	}
 
-	if cliOpts.Bearer == "" {
+	if cliOpts.Bearer == "" && cliOpts.Credentials == nil {
 		apiKey, err := getAPIKey()
 		if err != nil {
 			return nil, err
@@ -55,25 +75,41 @@ func consolidateClientOptionsForWorkspace(opts ...ClientOption) (*ClientOptions,
 		opt(cliOpts)
 	}
 
+	// After all the options, so that WithHTTPClient and WithBaseURL don't undo WithRouter.
+	if cliOpts.Router != nil {
+		cliOpts.HTTPClient = cliOpts.Router
+		cliOpts.BaseURL = routerBaseURL
+	}
+
 	if cliOpts.HTTPClient == nil {
 		cliOpts.HTTPClient = http.DefaultClient
 	}
 
+	if cliOpts.Credentials != nil {
+		cliOpts.HTTPClient = credentialsClient{next: cliOpts.HTTPClient, provider: cliOpts.Credentials}
+	}
+
+	// Around the other clients, so that the deadline covers their retries.
+	cliOpts.HTTPClient = newTimeoutClient(cliOpts)
+
 	dbCfg, err := loadDatabaseConfig(cliOpts)
 	if err != nil && cliOpts.BaseURL == "" {
 		return nil, nil, err
 	}
 
+	if cliOpts.Cache != nil {
+		workspaceID := dbCfg.workspaceID
+		if cliOpts.Router != nil {
+			workspaceID = cliOpts.Router.workspaceID
+		}
+		cliOpts.HTTPClient = cacheClient{next: cliOpts.HTTPClient, cache: cliOpts.Cache, workspaceID: workspaceID}
+	}
+
 	if cliOpts.BaseURL == "" {
-		cliOpts.BaseURL = fmt.Sprintf(
-			"https://%s.%s.%s",
-			dbCfg.workspaceID,
-			dbCfg.region,
-			dbCfg.domainWorkspace,
-		)
+		cliOpts.BaseURL = dbCfg.dataPlaneURL(dbCfg.region)
 	}
 
-	if cliOpts.Bearer == "" {
+	if cliOpts.Bearer == "" && cliOpts.Credentials == nil {
 		apiKey, err := getAPIKey()
 		if err != nil {
 			return nil, nil, err
@@ -91,6 +127,7 @@ type ClientOption func(*ClientOptions)
 func WithAPIKey(token string) func(options *ClientOptions) {
 	return func(options *ClientOptions) {
 		options.Bearer = token
+		options.Credentials = nil
 	}
 }
 
@@ -126,3 +163,34 @@ func WithBranch(branch string) func(options *ClientOptions) {
 		options.Branch = branch
 	}
 }
+
+// WithDataPlaneDomain sets the domain of the workspace API, xata.sh by default, e.g. for a
+// staging environment or a private endpoint. The requests go to
+// https://{workspace_id}.{region}.{domain}.
+func WithDataPlaneDomain(domain string) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		options.DataPlaneDomain = domain
+	}
+}
+
+// WithOAuthToken authenticates the requests with an OAuth access token, to act on behalf
+// of the user who authorized the app.
+func WithOAuthToken(token *OAuthToken) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		if token != nil {
+			options.Bearer = token.AccessToken
+			options.Credentials = nil
+		}
+	}
+}
+
+// WithCredentialsProvider authenticates every request with a token from the provider, e.g.
+// to pick up a rotated API key without rebuilding the client. When the API rejects a token,
+// the request is retried once with a new one.
+// It replaces WithAPIKey and WithOAuthToken, the last of these options wins.
+func WithCredentialsProvider(provider CredentialsProvider) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		options.Credentials = provider
+		options.Bearer = ""
+	}
+}
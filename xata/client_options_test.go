@@ -41,4 +41,9 @@ func TestClientOptions(t *testing.T) {
 		generatedwrapper.WithRegion(region)(c)
 		assert.Equal(t, region, c.Region)
 	})
+	t.Run("WithOAuthToken", func(t *testing.T) {
+		c := &generatedwrapper.ClientOptions{}
+		generatedwrapper.WithOAuthToken(&generatedwrapper.OAuthToken{AccessToken: "access-token"})(c)
+		assert.Equal(t, "access-token", c.Bearer)
+	})
 }
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+type ConversationTurn struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// Conversation is a multi-turn chat with a table. It keeps track of the session and the history
+// of the chat and can be serialized to JSON to be resumed later with ResumeConversation.
+type Conversation struct {
+	client SearchAndFilterClient
+	mu     sync.Mutex
+	state  conversationState
+}
+
+type conversationState struct {
+	DatabaseName *string `json:"databaseName,omitempty"`
+	BranchName   *string `json:"branchName,omitempty"`
+	TableName    string  `json:"tableName"`
+	SessionID    string  `json:"sessionId"`
+	// Settings are the search settings the conversation was started with, without the question.
+	// The session keeps them, the follow-ups only send their question.
+	Settings *xatagenworkspace.AskTableRequest `json:"settings,omitempty"`
+	History  []ConversationTurn                `json:"history"`
+}
+
+// StartConversation asks the first question of a conversation.
+func StartConversation(ctx context.Context, client SearchAndFilterClient, request AskTableRequest) (*Conversation, error) {
+	resp, err := client.Ask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := constructAskTableRequest(request.Payload)
+	settings.Question = ""
+
+	return &Conversation{
+		client: client,
+		state: conversationState{
+			DatabaseName: request.DatabaseName,
+			BranchName:   request.BranchName,
+			TableName:    request.TableName,
+			SessionID:    resp.SessionId,
+			Settings:     settings,
+			History: []ConversationTurn{
+				{Question: request.Payload.Question, Answer: resp.Answer},
+			},
+		},
+	}, nil
+}
+
+// ResumeConversation restores a conversation serialized with json.Marshal.
+func ResumeConversation(client SearchAndFilterClient, data []byte) (*Conversation, error) {
+	var state conversationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	if state.SessionID == "" {
+		return nil, fmt.Errorf("session ID cannot be empty")
+	}
+
+	return &Conversation{
+		client: client,
+		state:  state,
+	}, nil
+}
+
+// FollowUp asks a follow-up question and returns the answer.
+func (c *Conversation) FollowUp(ctx context.Context, question string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, err := c.client.AskFollowUp(ctx, AskFollowUpRequest{
+		BranchRequestOptional: BranchRequestOptional{
+			DatabaseName: c.state.DatabaseName,
+			BranchName:   c.state.BranchName,
+		},
+		TableName: c.state.TableName,
+		SessionID: c.state.SessionID,
+		Question:  question,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	c.state.History = append(c.state.History, ConversationTurn{Question: question, Answer: resp.Answer})
+
+	return resp.Answer, nil
+}
+
+// SessionID returns the ID of the chat session.
+func (c *Conversation) SessionID() string {
+	return c.state.SessionID
+}
+
+// TableName returns the name of the table the conversation is about.
+func (c *Conversation) TableName() string {
+	return c.state.TableName
+}
+
+// History returns the questions and answers of the conversation, oldest first.
+func (c *Conversation) History() []ConversationTurn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	history := make([]ConversationTurn, len(c.state.History))
+	copy(history, c.state.History)
+	return history
+}
+
+// MarshalJSON serializes the state of the conversation.
+func (c *Conversation) MarshalJSON() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return json.Marshal(c.state)
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+)
+
+func TestConversation(t *testing.T) {
+	var followUpPaths []string
+	var followUpBodies []map[string]any
+	testSrv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			// Not t.Fatal, the handler doesn't run in the test goroutine.
+			t.Error(err)
+			return
+		}
+
+		if strings.HasSuffix(request.URL.Path, "/ask") {
+			_ = json.NewEncoder(writer).Encode(map[string]string{"answer": "first answer", "sessionId": "session-1"})
+			return
+		}
+
+		followUpPaths = append(followUpPaths, request.URL.Path)
+		followUpBodies = append(followUpBodies, body)
+		_ = json.NewEncoder(writer).Encode(map[string]string{"answer": "answer to " + body["message"].(string)})
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	conv, err := xata.StartConversation(context.TODO(), cli, xata.AskTableRequest{
+		BranchRequestOptional: xata.BranchRequestOptional{
+			DatabaseName: xata.String("some-db"),
+			BranchName:   xata.String("main"),
+		},
+		TableName: "docs",
+		Payload: xata.AskTableRequestPayload{
+			Question: "first question",
+			Rules:    &[]string{"be concise"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "session-1", conv.SessionID())
+	assert.Equal(t, "docs", conv.TableName())
+
+	answer, err := conv.FollowUp(context.TODO(), "second question")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to second question", answer)
+
+	data, err := json.Marshal(conv)
+	assert.NoError(t, err)
+
+	resumed, err := xata.ResumeConversation(cli, data)
+	assert.NoError(t, err)
+	assert.Equal(t, conv.History(), resumed.History())
+
+	_, err = resumed.FollowUp(context.TODO(), "third question")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []xata.ConversationTurn{
+		{Question: "first question", Answer: "first answer"},
+		{Question: "second question", Answer: "answer to second question"},
+		{Question: "third question", Answer: "answer to third question"},
+	}, resumed.History())
+	assert.Len(t, conv.History(), 2)
+	assert.Equal(t, []string{
+		"/db/some-db:main/tables/docs/ask/session-1",
+		"/db/some-db:main/tables/docs/ask/session-1",
+	}, followUpPaths)
+	// The session keeps the settings of the first question, the follow-ups only send the question.
+	assert.Equal(t, []map[string]any{{"message": "second question"}, {"message": "third question"}}, followUpBodies)
+
+	resumedData, err := json.Marshal(resumed)
+	assert.NoError(t, err)
+	assert.Contains(t, string(resumedData), `"rules":["be concise"]`)
+
+	_, err = xata.ResumeConversation(cli, []byte(`{"tableName":"docs"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider returns the token authenticating each request, so that the token can
+// change during the lifetime of a client.
+type CredentialsProvider interface {
+	// Token returns the token for the next request.
+	Token(ctx context.Context) (string, error)
+	// Invalidate is called with a token the API rejected, before Token is called again.
+	Invalidate(token string)
+}
+
+// StaticCredentials always returns the same API key.
+func StaticCredentials(apiKey string) CredentialsProvider {
+	return staticCredentials(apiKey)
+}
+
+type staticCredentials string
+
+func (s staticCredentials) Token(context.Context) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("API key cannot be empty")
+	}
+	return string(s), nil
+}
+
+func (staticCredentials) Invalidate(string) {}
+
+// EnvCredentials reads the API key from an environment variable on every request.
+// The name defaults to XATA_API_KEY.
+func EnvCredentials(name string) CredentialsProvider {
+	if name == "" {
+		name = EnvXataAPIKey
+	}
+	return envCredentials(name)
+}
+
+type envCredentials string
+
+func (e envCredentials) Token(context.Context) (string, error) {
+	key := os.Getenv(string(e))
+	if key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return key, nil
+}
+
+func (envCredentials) Invalidate(string) {}
+
+// FileCredentials reads the API key from a file, e.g. a mounted secret. The file is read
+// again when it changes, or when the API rejected the key it holds.
+func FileCredentials(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileCredentials) Token(context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the API key: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the API key: %w", err)
+	}
+
+	key := strings.TrimSpace(string(content))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", f.path)
+	}
+
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
+
+func (f *fileCredentials) Invalidate(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == token {
+		f.key = ""
+	}
+}
+
+// OAuthCredentials authenticates with an OAuth access token, refreshed with the refresh
+// token when it expires or when the API rejects it.
+func OAuthCredentials(config OAuthConfig, token *OAuthToken) CredentialsProvider {
+	return &oauthCredentials{config: config, token: token}
+}
+
+type oauthCredentials struct {
+	config OAuthConfig
+
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+func (o *oauthCredentials) Token(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token.Valid() {
+		return o.token.AccessToken, nil
+	}
+
+	if o.token == nil || o.token.RefreshToken == "" {
+		return "", fmt.Errorf("OAuth token expired and can't be refreshed")
+	}
+
+	refreshed, err := o.config.Refresh(ctx, o.token.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = o.token.RefreshToken
+	}
+
+	o.token = refreshed
+	return refreshed.AccessToken, nil
+}
+
+func (o *oauthCredentials) Invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != nil && o.token.AccessToken == token {
+		expired := *o.token
+		expired.AccessToken = ""
+		o.token = &expired
+	}
+}
+
+// credentialsClient sets the token of the provider on every request, and retries once with
+// a new token when the API rejects the current one.
+type credentialsClient struct {
+	next     httpClient
+	provider CredentialsProvider
+}
+
+func (c credentialsClient) Do(req *http.Request) (*http.Response, error) {
+	token, err := c.provider.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get credentials: %w", err)
+	}
+
+	resp, err := c.do(req, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// A streamed body can't be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	c.provider.Invalidate(token)
+	refreshed, err := c.provider.Token(req.Context())
+	if err != nil || refreshed == token {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+
+	return c.do(retry, refreshed)
+}
+
+func (c credentialsClient) do(req *http.Request, token string) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return c.next.Do(req)
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// authService only accepts the current key, and records the keys and bodies it received.
+type authService struct {
+	t *testing.T
+
+	mu     sync.Mutex
+	key    string
+	tokens []string
+	bodies []string
+}
+
+func (a *authService) setKey(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.key = key
+}
+
+func (a *authService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(a.t, err)
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	a.tokens = append(a.tokens, token)
+	a.bodies = append(a.bodies, string(body))
+
+	if token != a.key {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid API key"})
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"inviteId": "invite-id"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id": "user-id"})
+}
+
+// rotatingCredentials hands out the old key until it's invalidated.
+type rotatingCredentials struct {
+	current     string
+	next        string
+	invalidated []string
+}
+
+func (r *rotatingCredentials) Token(context.Context) (string, error) {
+	return r.current, nil
+}
+
+func (r *rotatingCredentials) Invalidate(token string) {
+	r.invalidated = append(r.invalidated, token)
+	r.current = r.next
+}
+
+func TestWithCredentialsProvider(t *testing.T) {
+	t.Run("should retry once with a new token on 401", func(t *testing.T) {
+		svc := &authService{t: t, key: "new"}
+		testSrv := httptest.NewServer(svc)
+		defer testSrv.Close()
+
+		creds := &rotatingCredentials{current: "old", next: "new"}
+		cli, err := xata.NewInvitesClient(xata.WithBaseURL(testSrv.URL), xata.WithCredentialsProvider(creds), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		_, err = cli.Invite(context.TODO(), xata.InviteWorkspaceMemberRequest{Email: "jane@example.com", Role: xata.RoleOwner})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"old", "new"}, svc.tokens)
+		assert.Equal(t, []string{"old"}, creds.invalidated)
+		assert.Equal(t, svc.bodies[0], svc.bodies[1])
+		assert.Contains(t, svc.bodies[1], "jane@example.com")
+	})
+
+	t.Run("should not retry with the same token", func(t *testing.T) {
+		svc := &authService{t: t, key: "valid"}
+		testSrv := httptest.NewServer(svc)
+		defer testSrv.Close()
+
+		cli, err := xata.NewUsersClient(xata.WithBaseURL(testSrv.URL), xata.WithCredentialsProvider(xata.StaticCredentials("invalid")))
+		assert.NoError(t, err)
+
+		_, err = cli.Get(context.TODO())
+		assert.Error(t, err)
+		assert.Equal(t, []string{"invalid"}, svc.tokens)
+	})
+
+	t.Run("should let the last authentication option win", func(t *testing.T) {
+		svc := &authService{t: t, key: "api-key"}
+		testSrv := httptest.NewServer(svc)
+		defer testSrv.Close()
+
+		cli, err := xata.NewUsersClient(
+			xata.WithBaseURL(testSrv.URL),
+			xata.WithCredentialsProvider(xata.StaticCredentials("provider-key")),
+			xata.WithAPIKey("api-key"),
+		)
+		assert.NoError(t, err)
+
+		_, err = cli.Get(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api-key"}, svc.tokens)
+	})
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	assert.NoError(t, os.WriteFile(path, []byte("key-1\n"), 0o600))
+
+	svc := &authService{t: t, key: "key-1"}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	cli, err := xata.NewUsersClient(xata.WithBaseURL(testSrv.URL), xata.WithCredentialsProvider(xata.FileCredentials(path)))
+	assert.NoError(t, err)
+
+	_, err = cli.Get(context.TODO())
+	assert.NoError(t, err)
+
+	// The key is rotated: the file changes, and the old key stops working.
+	assert.NoError(t, os.WriteFile(path, []byte("key-2-rotated\n"), 0o600))
+	svc.setKey("key-2-rotated")
+
+	_, err = cli.Get(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key-1", "key-2-rotated"}, svc.tokens)
+
+	assert.NoError(t, os.Remove(path))
+	_, err = cli.Get(context.TODO())
+	assert.ErrorContains(t, err, "unable to get credentials")
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("MY_XATA_KEY", "env-key")
+
+	got, err := xata.EnvCredentials("MY_XATA_KEY").Token(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "env-key", got)
+
+	t.Setenv("MY_XATA_KEY", "")
+	_, err = xata.EnvCredentials("MY_XATA_KEY").Token(context.TODO())
+	assert.Error(t, err)
+}
+
+func TestOAuthCredentials(t *testing.T) {
+	var refreshes int
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "refresh", r.PostForm.Get("refresh_token"))
+
+		refreshes++
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "refreshed", "expires_in": 3600})
+	}))
+	defer tokenSrv.Close()
+
+	creds := xata.OAuthCredentials(
+		xata.OAuthConfig{ClientID: "client-id", TokenURL: tokenSrv.URL},
+		&xata.OAuthToken{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)},
+	)
+
+	got, err := creds.Token(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed", got)
+
+	got, err = creds.Token(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed", got)
+	assert.Equal(t, 1, refreshes)
+
+	creds.Invalidate("refreshed")
+	_, err = creds.Token(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, refreshes)
+
+	noRefresh := xata.OAuthCredentials(xata.OAuthConfig{ClientID: "client-id"}, &xata.OAuthToken{AccessToken: "access"})
+	got, err = noRefresh.Token(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "access", got)
+
+	noRefresh.Invalidate("access")
+	_, err = noRefresh.Token(context.TODO())
+	assert.Error(t, err)
+}
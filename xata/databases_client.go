@@ -4,10 +4,17 @@ package xata
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"sync"
 
 	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
 	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+	xatagenworkspaceclient "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go/core"
 )
 
 type UI xatagencore.CreateDatabaseRequestUi
@@ -34,6 +41,34 @@ type RenameDatabaseRequest struct {
 	WorkspaceID  *string
 }
 
+type DatabaseRequest struct {
+	DatabaseName string
+	WorkspaceID  *string
+}
+
+type UpdateDatabaseMetadataRequest struct {
+	DatabaseName string
+	WorkspaceID  *string
+	// Color is the color of the database in the Xata UI.
+	Color string
+}
+
+type UpdateDatabaseGithubSettingsRequest struct {
+	DatabaseName string
+	WorkspaceID  *string
+	// Owner is the user or organization owning the repository.
+	Owner string
+	Repo  string
+}
+
+// DatabaseDescription is the inventory of a database returned by Describe.
+type DatabaseDescription struct {
+	Metadata *xatagencore.DatabaseMetadata
+	Region   string
+	// Branches holds the details, including the schema, of every branch.
+	Branches []*xatagenworkspace.DbBranch
+}
+
 type DatabasesClient interface {
 	Create(ctx context.Context, request CreateDatabaseRequest) (*xatagencore.CreateDatabaseResponse, error)
 	Delete(ctx context.Context, request DeleteDatabaseRequest) (*xatagencore.DeleteDatabaseResponse, error)
@@ -42,13 +77,91 @@ type DatabasesClient interface {
 	List(ctx context.Context) (*xatagencore.ListDatabasesResponse, error)
 	ListWithWorkspaceID(ctx context.Context, workspaceID string) (*xatagencore.ListDatabasesResponse, error)
 	Rename(ctx context.Context, request RenameDatabaseRequest) (*xatagencore.DatabaseMetadata, error)
+	GetMetadata(ctx context.Context, request DatabaseRequest) (*xatagencore.DatabaseMetadata, error)
+	UpdateMetadata(ctx context.Context, request UpdateDatabaseMetadataRequest) (*xatagencore.DatabaseMetadata, error)
+	GetGithubSettings(ctx context.Context, request DatabaseRequest) (*xatagencore.DatabaseGithubSettings, error)
+	UpdateGithubSettings(ctx context.Context, request UpdateDatabaseGithubSettingsRequest) (*xatagencore.DatabaseGithubSettings, error)
+	DeleteGithubSettings(ctx context.Context, request DatabaseRequest) error
+	Describe(ctx context.Context, request DatabaseRequest) (*DatabaseDescription, error)
 }
 
+// describeConcurrency is the number of branch details Describe fetches at once.
+const describeConcurrency = 4
+
 type databaseCli struct {
 	generated   xatagencore.DatabasesClient
 	WorkspaceID string
 	BranchName  string
 	Region      string
+
+	httpClient httpClient
+	baseURL    string
+	bearer     string
+	// dbCfg builds the data plane URL of the branch requests in Describe.
+	dbCfg databaseConfig
+}
+
+func (d databaseCli) resolveDatabase(dbName string, workspaceID *string) (string, error) {
+	if dbName == "" {
+		return "", fmt.Errorf("database name cannot be empty")
+	}
+	return resolveWorkspaceID(workspaceID, d.WorkspaceID)
+}
+
+// githubSettings sends a request to the GitHub settings of a database, an endpoint missing from
+// the API specs the generated client is built from.
+func (d databaseCli) githubSettings(ctx context.Context, method, workspaceID, dbName string, request, response any) error {
+	endpointURL := fmt.Sprintf(d.baseURL+"/"+"workspaces/%v/dbs/%v/github", workspaceID, dbName)
+
+	header := http.Header{}
+	if d.bearer != "" {
+		header.Set("Authorization", "Bearer "+d.bearer)
+	}
+
+	return xatagenclient.DoRequest(ctx, d.httpClient, endpointURL, method, request, response, false, header, decodeCoreError)
+}
+
+// coreErrors are the generated errors of the core API, by status code.
+var coreErrors = map[int]func(*xatagenclient.APIError) apiErrorBody{
+	http.StatusBadRequest: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.BadRequestError{APIError: e}
+	},
+	http.StatusUnauthorized: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.UnauthorizedError{APIError: e}
+	},
+	http.StatusForbidden: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.ForbiddenError{APIError: e}
+	},
+	http.StatusNotFound: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.NotFoundError{APIError: e}
+	},
+	http.StatusConflict: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.ConflictError{APIError: e}
+	},
+	http.StatusUnprocessableEntity: func(e *xatagenclient.APIError) apiErrorBody {
+		return &xatagencore.UnprocessableEntityError{APIError: e}
+	},
+}
+
+// decodeCoreError decodes an error response of the core API into its generated error, as the
+// generated client does.
+func decodeCoreError(statusCode int, body io.Reader) error {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	apiError := xatagenclient.NewAPIError(statusCode, errors.New(string(raw)))
+	newErr, ok := coreErrors[statusCode]
+	if !ok {
+		return apiError
+	}
+
+	value := newErr(apiError)
+	if err := value.UnmarshalJSON(raw); err != nil {
+		return err
+	}
+	return value
 }
 
 // Create creates a database.
@@ -136,6 +249,152 @@ func (d databaseCli) Rename(ctx context.Context, request RenameDatabaseRequest)
 	)
 }
 
+// GetMetadata retrieves the metadata of a database.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/dbs/db_name#get-database-metadata
+func (d databaseCli) GetMetadata(ctx context.Context, request DatabaseRequest) (*xatagencore.DatabaseMetadata, error) {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.generated.GetDatabaseMetadata(ctx, wsID, request.DatabaseName)
+}
+
+// UpdateMetadata updates the color of a database in the Xata UI.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/dbs/db_name#update-database-metadata
+func (d databaseCli) UpdateMetadata(ctx context.Context, request UpdateDatabaseMetadataRequest) (*xatagencore.DatabaseMetadata, error) {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Color == "" {
+		return nil, fmt.Errorf("color cannot be empty")
+	}
+
+	return d.generated.UpdateDatabaseMetadata(ctx, wsID, request.DatabaseName, &xatagencore.UpdateDatabaseMetadataRequest{
+		Ui: &xatagencore.UpdateDatabaseMetadataRequestUi{Color: String(request.Color)},
+	})
+}
+
+// GetGithubSettings retrieves the GitHub repository a database is mapped to.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/dbs/db_name/github#get-database-github-settings
+func (d databaseCli) GetGithubSettings(ctx context.Context, request DatabaseRequest) (*xatagencore.DatabaseGithubSettings, error) {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *xatagencore.DatabaseGithubSettings
+	if err := d.githubSettings(ctx, http.MethodGet, wsID, request.DatabaseName, nil, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// UpdateGithubSettings maps a database to a GitHub repository, for Xata to create preview
+// branches for its pull requests.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/dbs/db_name/github#update-database-github-settings
+func (d databaseCli) UpdateGithubSettings(ctx context.Context, request UpdateDatabaseGithubSettingsRequest) (*xatagencore.DatabaseGithubSettings, error) {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Owner == "" || request.Repo == "" {
+		return nil, fmt.Errorf("repository owner and name cannot be empty")
+	}
+
+	var response *xatagencore.DatabaseGithubSettings
+	err = d.githubSettings(ctx, http.MethodPut, wsID, request.DatabaseName, &xatagencore.DatabaseGithubSettings{
+		Owner: request.Owner,
+		Repo:  request.Repo,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteGithubSettings removes the GitHub repository mapping of a database.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/dbs/db_name/github#delete-database-github-settings
+func (d databaseCli) DeleteGithubSettings(ctx context.Context, request DatabaseRequest) error {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	return d.githubSettings(ctx, http.MethodDelete, wsID, request.DatabaseName, nil, nil)
+}
+
+// Describe retrieves the metadata, the region and the details of every branch of a database.
+func (d databaseCli) Describe(ctx context.Context, request DatabaseRequest) (*DatabaseDescription, error) {
+	wsID, err := d.resolveDatabase(request.DatabaseName, request.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := d.GetMetadata(ctx, DatabaseRequest{DatabaseName: request.DatabaseName, WorkspaceID: &wsID})
+	if err != nil {
+		return nil, err
+	}
+
+	dbCfg := d.dbCfg
+	dbCfg.workspaceID = wsID
+	baseURL := dbCfg.dataPlaneURL(metadata.Region)
+
+	branches := xatagenworkspace.NewBranchClient(
+		func(options *xatagenworkspaceclient.ClientOptions) {
+			options.HTTPClient = d.httpClient
+			options.BaseURL = baseURL
+			options.Bearer = d.bearer
+		})
+
+	list, err := branches.GetBranchList(ctx, request.DatabaseName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the branches: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	details := make([]*xatagenworkspace.DbBranch, len(list.Branches))
+	sem := make(chan struct{}, describeConcurrency)
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		firstErr error
+	)
+	for i, b := range list.Branches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, branchName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			branch, err := branches.GetBranchDetails(ctx, fmt.Sprintf("%s:%s", request.DatabaseName, branchName))
+			if err != nil {
+				// The first error cancels the other fetches, whose errors are only a consequence.
+				failOnce.Do(func() {
+					firstErr = fmt.Errorf("unable to get the details of branch %s: %w", branchName, err)
+					cancel()
+				})
+				return
+			}
+			details[i] = branch
+		}(i, b.Name)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return &DatabaseDescription{
+		Metadata: metadata,
+		Region:   metadata.Region,
+		Branches: details,
+	}, nil
+}
+
 // NewDatabasesClient constructs a client for interacting with databases.
 func NewDatabasesClient(opts ...ClientOption) (DatabasesClient, error) {
 	cliOpts, err := consolidateClientOptionsForCore(opts...)
@@ -159,5 +418,9 @@ func NewDatabasesClient(opts ...ClientOption) (DatabasesClient, error) {
 		WorkspaceID: dbCfg.workspaceID,
 		Region:      dbCfg.region,
 		BranchName:  dbCfg.branchName,
+		httpClient:  cliOpts.HTTPClient,
+		baseURL:     cliOpts.BaseURL,
+		bearer:      cliOpts.Bearer,
+		dbCfg:       dbCfg,
 	}, nil
 }
@@ -4,7 +4,14 @@ package xata_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -444,3 +451,237 @@ func Test_databaseCli_Rename(t *testing.T) {
 		})
 	}
 }
+
+func Test_databaseCli_GetMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagen.DatabaseMetadata
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should get the database metadata",
+			want: &xatagen.DatabaseMetadata{
+				Name:      "test-db",
+				Region:    "us-east-1",
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Ui:        &xatagen.DatabaseMetadataUi{Color: xata.String("red")},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, eTC := range errTestCasesCore {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodGet, "/workspaces/ws-id/dbs/test-db", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+			assert.NoError(err)
+
+			got, err := cli.GetMetadata(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db"})
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_databaseCli_UpdateMetadata(t *testing.T) {
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/workspaces/other-ws/dbs/test-db", r.URL.Path)
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"ui": map[string]any{"color": "blue"}}, body)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "test-db", "ui": map[string]any{"color": "blue"}})
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+	assert.NoError(t, err)
+
+	got, err := cli.UpdateMetadata(context.TODO(), xata.UpdateDatabaseMetadataRequest{
+		DatabaseName: "test-db",
+		WorkspaceID:  xata.String("other-ws"),
+		Color:        "blue",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "blue", *got.Ui.Color)
+
+	_, err = cli.UpdateMetadata(context.TODO(), xata.UpdateDatabaseMetadataRequest{DatabaseName: "test-db"})
+	assert.EqualError(t, err, "color cannot be empty")
+	_, err = cli.UpdateMetadata(context.TODO(), xata.UpdateDatabaseMetadataRequest{Color: "blue"})
+	assert.EqualError(t, err, "database name cannot be empty")
+}
+
+func Test_databaseCli_GithubSettings(t *testing.T) {
+	settings := &xatagen.DatabaseGithubSettings{Owner: "xataio", Repo: "xata-go"}
+
+	t.Run("should get the GitHub settings", func(t *testing.T) {
+		testSrv := testService(t, http.MethodGet, "/workspaces/ws-id/dbs/test-db/github", http.StatusOK, false, settings)
+		defer testSrv.Close()
+
+		cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		got, err := cli.GetGithubSettings(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db"})
+		assert.NoError(t, err)
+		assert.Equal(t, settings, got)
+	})
+
+	t.Run("should update the GitHub settings", func(t *testing.T) {
+		testSrv := testService(t, http.MethodPut, "/workspaces/ws-id/dbs/test-db/github", http.StatusOK, false, settings)
+		defer testSrv.Close()
+
+		cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		got, err := cli.UpdateGithubSettings(context.TODO(), xata.UpdateDatabaseGithubSettingsRequest{
+			DatabaseName: "test-db",
+			Owner:        "xataio",
+			Repo:         "xata-go",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, settings, got)
+
+		_, err = cli.UpdateGithubSettings(context.TODO(), xata.UpdateDatabaseGithubSettingsRequest{DatabaseName: "test-db"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should delete the GitHub settings", func(t *testing.T) {
+		testSrv := testService(t, http.MethodDelete, "/workspaces/ws-id/dbs/test-db/github", http.StatusNoContent, false, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		assert.NoError(t, cli.DeleteGithubSettings(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db"}))
+	})
+
+	t.Run("should return the API errors", func(t *testing.T) {
+		testSrv := testService(t, http.MethodGet, "/workspaces/ws-id/dbs/test-db/github", http.StatusNotFound, true, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewDatabasesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		got, err := cli.GetGithubSettings(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db"})
+		assert.Nil(t, got)
+		var notFound *xatagen.NotFoundError
+		assert.ErrorAs(t, err, &notFound)
+	})
+}
+
+func Test_databaseCli_Describe(t *testing.T) {
+	var (
+		inFlight, maxInFlight atomic.Int32
+		mu                    sync.Mutex
+		branchHosts           = map[string]bool{}
+	)
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch r.URL.Path {
+		case "/workspaces/other-ws/dbs/test-db":
+			resp = map[string]any{"name": "test-db", "region": "eu-west-1"}
+		case "/dbs/test-db":
+			var branches []map[string]any
+			for i := 0; i < 10; i++ {
+				branches = append(branches, map[string]any{"name": fmt.Sprintf("branch-%d", i)})
+			}
+			resp = map[string]any{"databaseName": "test-db", "branches": branches}
+		default:
+			branchName, ok := strings.CutPrefix(r.URL.Path, "/db/test-db:")
+			if !ok {
+				// Not t.Fatal, the handler doesn't run in the test goroutine.
+				t.Errorf("unexpected path: %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if n <= seen || maxInFlight.CompareAndSwap(seen, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			resp = map[string]any{"databaseName": "test-db", "branchName": branchName, "id": "bb_" + branchName}
+		}
+		if r.URL.Path != "/workspaces/other-ws/dbs/test-db" {
+			mu.Lock()
+			branchHosts[r.Header.Get("X-Routed-Host")] = true
+			mu.Unlock()
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer testSrv.Close()
+
+	target, err := url.Parse(testSrv.URL)
+	assert.NoError(t, err)
+
+	newClient := func(t *testing.T, opts ...xata.ClientOption) xata.DatabasesClient {
+		opts = append(opts,
+			xata.WithBaseURL(testSrv.URL),
+			xata.WithAPIKey("test-key"),
+			xata.WithWorkspaceID("ws-id"),
+			xata.WithHTTPClient(hostRecorder{target: target}),
+		)
+		cli, err := xata.NewDatabasesClient(opts...)
+		assert.NoError(t, err)
+		return cli
+	}
+
+	t.Run("should describe the database from its region", func(t *testing.T) {
+		branchHosts = map[string]bool{}
+		cli := newClient(t)
+
+		got, err := cli.Describe(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db", WorkspaceID: xata.String("other-ws")})
+		assert.NoError(t, err)
+		assert.Equal(t, "test-db", got.Metadata.Name)
+		assert.Equal(t, "eu-west-1", got.Region)
+		assert.Len(t, got.Branches, 10)
+		assert.Equal(t, "branch-0", got.Branches[0].BranchName)
+		assert.Equal(t, "bb_branch-9", got.Branches[9].Id)
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(4))
+		assert.Equal(t, map[string]bool{"other-ws.eu-west-1.xata.sh": true}, branchHosts)
+
+		_, err = cli.Describe(context.TODO(), xata.DatabaseRequest{})
+		assert.EqualError(t, err, "database name cannot be empty")
+	})
+
+	t.Run("should use the data plane domain", func(t *testing.T) {
+		branchHosts = map[string]bool{}
+		cli := newClient(t, xata.WithDataPlaneDomain("staging-xata.dev"))
+
+		_, err := cli.Describe(context.TODO(), xata.DatabaseRequest{DatabaseName: "test-db", WorkspaceID: xata.String("other-ws")})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"other-ws.eu-west-1.staging-xata.dev": true}, branchHosts)
+	})
+}
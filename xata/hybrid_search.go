@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+type HybridFusionMethod uint8
+
+const (
+	// HybridFusionReciprocalRank scores each record with the sum of `weight / (k + rank)` over the
+	// result lists it appears in.
+	HybridFusionReciprocalRank HybridFusionMethod = iota + 1
+	// HybridFusionWeightedScore min-max normalizes the scores of each result list to [0, 1]
+	// and scores each record with the weighted sum of its normalized scores.
+	HybridFusionWeightedScore
+)
+
+// defaultRankConstant is the `k` constant of the reciprocal rank fusion.
+const defaultRankConstant = 60
+
+type HybridSearchRequestPayload struct {
+	// Keyword is the free text search, its Filter is not applied to the vector search.
+	Keyword SearchTableRequestPayload
+	// Vector is the vector similarity search.
+	Vector VectorSearchTableRequestPayload
+	// Fusion is the method used to merge the results, defaults to HybridFusionReciprocalRank.
+	Fusion HybridFusionMethod
+	// RankConstant is the `k` constant of the reciprocal rank fusion, defaults to 60.
+	RankConstant *float64
+	// KeywordWeight is the weight of the keyword results, defaults to 1.
+	KeywordWeight *float64
+	// VectorWeight is the weight of the vector results, defaults to 1.
+	VectorWeight *float64
+	// Size limits the number of merged results. All results are returned if not set.
+	Size *int
+}
+
+type HybridSearchRequest struct {
+	BranchRequestOptional
+	TableName string
+	Payload   HybridSearchRequestPayload
+}
+
+type HybridSearchResult struct {
+	Record
+	// Score is the fused score the results are ordered by.
+	Score float64
+	// KeywordRank is the 1-based position in the keyword results, 0 if the record wasn't found.
+	KeywordRank  int
+	KeywordScore *float64
+	// VectorRank is the 1-based position in the vector results, 0 if the record wasn't found.
+	VectorRank  int
+	VectorScore *float64
+	// Highlight holds the highlights of the keyword search.
+	Highlight map[string]*xatagenworkspace.RecordMetaXataHighlightValue
+}
+
+type HybridSearchResponse struct {
+	Results           []*HybridSearchResult
+	KeywordTotalCount int
+	VectorTotalCount  int
+}
+
+// HybridSearch runs a free text search and a vector similarity search concurrently with the
+// client and merges the results into a single list ranked by the fused score. Records found by
+// both searches are returned once.
+func HybridSearch(ctx context.Context, client SearchAndFilterClient, request HybridSearchRequest) (*HybridSearchResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg          sync.WaitGroup
+		once        sync.Once
+		firstErr    error
+		keywordResp *xatagenworkspace.SearchTableResponse
+		vectorResp  *xatagenworkspace.VectorSearchTableResponse
+	)
+
+	// fail keeps the first error and cancels the other search.
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		resp, err := client.SearchTable(ctx, SearchTableRequest{
+			BranchRequestOptional: request.BranchRequestOptional,
+			TableName:             request.TableName,
+			Payload:               request.Payload.Keyword,
+		})
+		if err != nil {
+			fail(err)
+			return
+		}
+		keywordResp = resp
+	}()
+	go func() {
+		defer wg.Done()
+		resp, err := client.VectorSearch(ctx, VectorSearchTableRequest{
+			BranchRequestOptional: request.BranchRequestOptional,
+			TableName:             request.TableName,
+			Payload:               request.Payload.Vector,
+		})
+		if err != nil {
+			fail(err)
+			return
+		}
+		vectorResp = resp
+	}()
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return fuseHybridResults(keywordResp, vectorResp, request.Payload)
+}
+
+func fuseHybridResults(
+	keywordResp *xatagenworkspace.SearchTableResponse,
+	vectorResp *xatagenworkspace.VectorSearchTableResponse,
+	payload HybridSearchRequestPayload,
+) (*HybridSearchResponse, error) {
+	keywordWeight := 1.0
+	if payload.KeywordWeight != nil {
+		keywordWeight = *payload.KeywordWeight
+	}
+
+	vectorWeight := 1.0
+	if payload.VectorWeight != nil {
+		vectorWeight = *payload.VectorWeight
+	}
+
+	rankConstant := float64(defaultRankConstant)
+	if payload.RankConstant != nil {
+		rankConstant = *payload.RankConstant
+	}
+
+	byID := make(map[string]*HybridSearchResult)
+	var results []*HybridSearchResult
+
+	// merge adds a result list, calling set for each record with its 1-based rank and raw score.
+	merge := func(records []*xatagenworkspace.Record, set func(res *HybridSearchResult, rank int, score *float64)) error {
+		for i, rec := range records {
+			if rec == nil {
+				continue
+			}
+
+			record, err := constructRecord(*rec)
+			if err != nil {
+				return err
+			}
+
+			res, ok := byID[record.Id]
+			if !ok {
+				res = &HybridSearchResult{Record: *record}
+				byID[record.Id] = res
+				results = append(results, res)
+			}
+
+			var score *float64
+			if record.Xata != nil {
+				score = record.Xata.Score
+			}
+			set(res, i+1, score)
+		}
+		return nil
+	}
+
+	response := &HybridSearchResponse{}
+
+	if keywordResp != nil {
+		response.KeywordTotalCount = keywordResp.TotalCount
+		err := merge(keywordResp.Records, func(res *HybridSearchResult, rank int, score *float64) {
+			res.KeywordRank = rank
+			res.KeywordScore = score
+			if xataMeta := res.Xata; xataMeta != nil && xataMeta.Highlight != nil {
+				res.Highlight = *xataMeta.Highlight
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if vectorResp != nil {
+		response.VectorTotalCount = vectorResp.TotalCount
+		err := merge(vectorResp.Records, func(res *HybridSearchResult, rank int, score *float64) {
+			res.VectorRank = rank
+			res.VectorScore = score
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch payload.Fusion {
+	case HybridFusionWeightedScore:
+		keywordNorm := scoreNormalizer(results, func(r *HybridSearchResult) *float64 { return r.KeywordScore })
+		vectorNorm := scoreNormalizer(results, func(r *HybridSearchResult) *float64 { return r.VectorScore })
+		for _, res := range results {
+			if res.KeywordScore != nil {
+				res.Score += keywordWeight * keywordNorm(*res.KeywordScore)
+			}
+			if res.VectorScore != nil {
+				res.Score += vectorWeight * vectorNorm(*res.VectorScore)
+			}
+		}
+	default:
+		for _, res := range results {
+			if res.KeywordRank > 0 {
+				res.Score += keywordWeight / (rankConstant + float64(res.KeywordRank))
+			}
+			if res.VectorRank > 0 {
+				res.Score += vectorWeight / (rankConstant + float64(res.VectorRank))
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if payload.Size != nil && *payload.Size >= 0 && *payload.Size < len(results) {
+		results = results[:*payload.Size]
+	}
+
+	response.Results = results
+
+	return response, nil
+}
+
+// scoreNormalizer returns a function mapping the scores selected by score to [0, 1].
+// If all scores are equal, they are mapped to 1.
+func scoreNormalizer(results []*HybridSearchResult, score func(*HybridSearchResult) *float64) func(float64) float64 {
+	var minScore, maxScore float64
+	found := false
+	for _, res := range results {
+		s := score(res)
+		if s == nil {
+			continue
+		}
+		if !found || *s < minScore {
+			minScore = *s
+		}
+		if !found || *s > maxScore {
+			maxScore = *s
+		}
+		found = true
+	}
+
+	return func(s float64) float64 {
+		if maxScore == minScore {
+			return 1
+		}
+		return (s - minScore) / (maxScore - minScore)
+	}
+}
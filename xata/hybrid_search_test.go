@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+)
+
+func hybridSearchTestService(t *testing.T, keywordStatus int, keyword, vector any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		status, body := http.StatusOK, vector
+		if strings.HasSuffix(request.URL.Path, "/search") {
+			status, body = keywordStatus, keyword
+		}
+
+		writer.WriteHeader(status)
+		if status != http.StatusOK {
+			body = testErrBody
+		}
+
+		// Not t.Fatal, the handler doesn't run in the test goroutine.
+		if err := json.NewEncoder(writer).Encode(body); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestHybridSearch(t *testing.T) {
+	keyword := map[string]any{
+		"totalCount": 2,
+		"records": []map[string]any{
+			{"id": "a", "name": "alpha", "xata": map[string]any{"score": 10, "highlight": map[string]any{"name": []string{"<em>alpha</em>"}}}},
+			{"id": "b", "name": "beta", "xata": map[string]any{"score": 5}},
+		},
+	}
+	vector := map[string]any{
+		"totalCount": 2,
+		"records": []map[string]any{
+			{"id": "b", "name": "beta", "xata": map[string]any{"score": 0.9}},
+			{"id": "c", "name": "gamma", "xata": map[string]any{"score": 0.1}},
+		},
+	}
+
+	t.Run("should fuse results with reciprocal rank fusion", func(t *testing.T) {
+		testSrv := hybridSearchTestService(t, http.StatusOK, keyword, vector)
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		got, err := xata.HybridSearch(context.TODO(), cli, xata.HybridSearchRequest{
+			BranchRequestOptional: xata.BranchRequestOptional{DatabaseName: xata.String("db"), BranchName: xata.String("main")},
+			TableName:             "items",
+			Payload: xata.HybridSearchRequestPayload{
+				Keyword: xata.SearchTableRequestPayload{Query: "alpha"},
+				Vector:  xata.VectorSearchTableRequestPayload{Column: "embedding", QueryVector: []float64{0.1}},
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Len(t, got.Results, 3)
+		assert.Equal(t, "b", got.Results[0].Id)
+		assert.Equal(t, 2, got.Results[0].KeywordRank)
+		assert.Equal(t, 1, got.Results[0].VectorRank)
+		assert.Equal(t, "a", got.Results[1].Id)
+		assert.Equal(t, []string{"<em>alpha</em>"}, got.Results[1].Highlight["name"].StringList)
+		assert.Equal(t, "c", got.Results[2].Id)
+		assert.Equal(t, 0, got.Results[2].KeywordRank)
+		assert.Equal(t, 2, got.KeywordTotalCount)
+		assert.Equal(t, 2, got.VectorTotalCount)
+	})
+
+	t.Run("should fuse results with weighted scores", func(t *testing.T) {
+		testSrv := hybridSearchTestService(t, http.StatusOK, keyword, vector)
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		got, err := xata.HybridSearch(context.TODO(), cli, xata.HybridSearchRequest{
+			BranchRequestOptional: xata.BranchRequestOptional{DatabaseName: xata.String("db"), BranchName: xata.String("main")},
+			TableName:             "items",
+			Payload: xata.HybridSearchRequestPayload{
+				Fusion:        xata.HybridFusionWeightedScore,
+				KeywordWeight: xata.Float64(2),
+				Size:          xata.Int(2),
+			},
+		})
+		assert.NoError(t, err)
+
+		assert.Len(t, got.Results, 2)
+		assert.Equal(t, "a", got.Results[0].Id)
+		assert.Equal(t, 2.0, got.Results[0].Score)
+		assert.Equal(t, "b", got.Results[1].Id)
+		assert.Equal(t, 1.0, got.Results[1].Score)
+	})
+
+	t.Run("should return the error of a failed search", func(t *testing.T) {
+		testSrv := hybridSearchTestService(t, http.StatusBadRequest, keyword, vector)
+		defer testSrv.Close()
+
+		cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		got, err := xata.HybridSearch(context.TODO(), cli, xata.HybridSearchRequest{
+			BranchRequestOptional: xata.BranchRequestOptional{DatabaseName: xata.String("db"), BranchName: xata.String("main")},
+			TableName:             "items",
+		})
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
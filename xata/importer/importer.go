@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package importer loads CSV and NDJSON data into Xata tables.
+//
+// The column types are inferred from the data, the table and its columns can optionally be created
+// and the rows are inserted in batches through the bulk insert endpoint. Rows that cannot be
+// converted to the inferred (or overridden) column types are skipped and reported by line number.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xataio/xata-go/xata"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+// Format is the format of the imported data.
+type Format uint8
+
+const (
+	FormatCSV Format = iota + 1
+	FormatNDJSON
+)
+
+const (
+	// defaultBatchSize is the number of records sent per bulk insert request.
+	defaultBatchSize = 500
+	// maxBatchSize is the maximum number of records accepted by the bulk insert endpoint.
+	maxBatchSize = 1000
+	// idColumn is the column holding the record ID, it is never created.
+	idColumn = "id"
+)
+
+type Options struct {
+	xata.RecordRequest
+	Format Format
+	// Mapping renames source fields (CSV headers or NDJSON keys) to column names.
+	// A field mapped to an empty string is skipped. Unmapped fields keep their name.
+	Mapping map[string]string
+	// TypeOverrides sets the column type for the given column names instead of inferring it.
+	TypeOverrides map[string]xata.ColumnType
+	// CreateTable creates the table and its columns before inserting the records.
+	CreateTable bool
+	// BatchSize is the number of records per bulk insert request, defaults to 500 and is capped at 1000.
+	BatchSize int
+	// DryRun infers the schema and validates the rows without writing anything.
+	DryRun bool
+}
+
+// ColumnSchema describes a column of the imported table.
+type ColumnSchema struct {
+	Name   string
+	Source string
+	Type   xata.ColumnType
+}
+
+type Schema []ColumnSchema
+
+// String renders the schema as a table, one column per line.
+func (s Schema) String() string {
+	var b strings.Builder
+	for _, c := range s {
+		fmt.Fprintf(&b, "%s\t%s", c.Name, columnTypeName(c.Type))
+		if c.Source != c.Name {
+			fmt.Fprintf(&b, "\t(from %s)", c.Source)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// RowError is a rejected row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Report is the outcome of an import.
+type Report struct {
+	Schema   Schema
+	Inserted int
+	Rejected []RowError
+	DryRun   bool
+}
+
+type Importer struct {
+	tables  xata.TableClient
+	records xata.RecordsClient
+}
+
+// New constructs an importer. The table client is only used when Options.CreateTable is set.
+func New(tables xata.TableClient, records xata.RecordsClient) *Importer {
+	return &Importer{
+		tables:  tables,
+		records: records,
+	}
+}
+
+// Import reads all rows from r, infers the schema and inserts the rows into the table.
+// The returned report is non-nil whenever the data could be read, even if inserting failed.
+func (i *Importer) Import(ctx context.Context, r io.Reader, opts Options) (*Report, error) {
+	if opts.TableName == "" {
+		return nil, fmt.Errorf("table name cannot be empty")
+	}
+
+	fields, rows, err := read(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Schema: inferSchema(fields, rows, opts),
+		DryRun: opts.DryRun,
+	}
+
+	var (
+		records []map[string]*xata.DataInputRecordValue
+		lines   []int
+	)
+	for _, rw := range rows {
+		if rw.err != nil {
+			report.Rejected = append(report.Rejected, RowError{Line: rw.line, Err: rw.err})
+			continue
+		}
+
+		record, err := buildRecord(report.Schema, rw.values)
+		if err != nil {
+			report.Rejected = append(report.Rejected, RowError{Line: rw.line, Err: err})
+			continue
+		}
+
+		records = append(records, record)
+		lines = append(lines, rw.line)
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if opts.CreateTable {
+		if err := i.createTable(ctx, opts.RecordRequest, report.Schema); err != nil {
+			return report, err
+		}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if batchSize > maxBatchSize {
+		batchSize = maxBatchSize
+	}
+
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		_, err := i.records.BulkInsert(ctx, xata.BulkInsertRecordRequest{
+			RecordRequest: opts.RecordRequest,
+			Columns:       []string{idColumn},
+			Records:       records[start:end],
+		})
+		if err != nil {
+			return report, fmt.Errorf("inserting rows from line %d to %d: %w", lines[start], lines[end-1], err)
+		}
+
+		report.Inserted += end - start
+	}
+
+	return report, nil
+}
+
+func (i *Importer) createTable(ctx context.Context, request xata.RecordRequest, schema Schema) error {
+	tableRequest := xata.TableRequest{
+		DatabaseName: request.DatabaseName,
+		BranchName:   request.BranchName,
+		TableName:    request.TableName,
+	}
+
+	if _, err := i.tables.Create(ctx, tableRequest); err != nil {
+		return err
+	}
+
+	for _, c := range schema {
+		if c.Name == idColumn {
+			continue
+		}
+
+		_, err := i.tables.AddColumn(ctx, xata.AddColumnRequest{
+			TableRequest: tableRequest,
+			Column: &xata.Column{
+				Name: c.Name,
+				Type: c.Type,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("adding column %s: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// inferSchema returns a column per mapped field, in the order the fields were read.
+// Columns without any non-null value are inferred as `string`.
+func inferSchema(fields []string, rows []row, opts Options) Schema {
+	var schema Schema
+	for _, field := range fields {
+		name := field
+		if mapped, ok := opts.Mapping[field]; ok {
+			if mapped == "" {
+				continue
+			}
+			name = mapped
+		}
+
+		if override, ok := opts.TypeOverrides[name]; ok {
+			schema = append(schema, ColumnSchema{Name: name, Source: field, Type: override})
+			continue
+		}
+
+		if name == idColumn {
+			schema = append(schema, ColumnSchema{Name: name, Source: field, Type: xata.ColumnTypeString})
+			continue
+		}
+
+		var columnType xata.ColumnType
+		for _, rw := range rows {
+			if rw.err != nil {
+				continue
+			}
+			if t, ok := inferValue(rw.values[field]); ok {
+				columnType = widen(columnType, t)
+			}
+		}
+		if columnType == 0 {
+			columnType = xata.ColumnTypeString
+		}
+
+		schema = append(schema, ColumnSchema{Name: name, Source: field, Type: columnType})
+	}
+
+	return schema
+}
+
+func buildRecord(schema Schema, values map[string]any) (map[string]*xata.DataInputRecordValue, error) {
+	record := make(map[string]*xata.DataInputRecordValue, len(schema))
+	for _, c := range schema {
+		value := values[c.Source]
+		if isNull(value) {
+			continue
+		}
+
+		converted, err := convert(value, c.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", c.Name, err)
+		}
+		record[c.Name] = converted
+	}
+
+	return record, nil
+}
+
+func isNull(value any) bool {
+	if value == nil {
+		return true
+	}
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return true
+	}
+	return false
+}
+
+func columnTypeName(t xata.ColumnType) string {
+	return xatagenworkspace.ColumnType(t).String()
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+	"github.com/xataio/xata-go/xata/importer"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+type fakeTableClient struct {
+	xata.TableClient
+	created []string
+	columns []xata.Column
+}
+
+func (f *fakeTableClient) Create(_ context.Context, request xata.TableRequest) (*xatagenworkspace.CreateTableResponse, error) {
+	f.created = append(f.created, request.TableName)
+	return &xatagenworkspace.CreateTableResponse{TableName: request.TableName}, nil
+}
+
+func (f *fakeTableClient) AddColumn(_ context.Context, request xata.AddColumnRequest) (*xatagenworkspace.AddTableColumnResponse, error) {
+	f.columns = append(f.columns, *request.Column)
+	return &xatagenworkspace.AddTableColumnResponse{}, nil
+}
+
+type fakeRecordsClient struct {
+	xata.RecordsClient
+	batches [][]map[string]*xata.DataInputRecordValue
+	err     error
+}
+
+func (f *fakeRecordsClient) BulkInsert(_ context.Context, request xata.BulkInsertRecordRequest) ([]*xata.Record, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.batches = append(f.batches, request.Records)
+	return nil, nil
+}
+
+const testCSV = `name,age,score,active,joined,tags
+Alice,30,1.5,true,2023-01-02,"[""a"",""b""]"
+Bob,x,2,false,2023-01-03T10:00:00Z,[]
+Carol,41,3,true,,
+`
+
+func TestImporter_Import(t *testing.T) {
+	t.Run("should infer the schema from CSV and report rejected rows", func(t *testing.T) {
+		tables := &fakeTableClient{}
+		records := &fakeRecordsClient{}
+
+		report, err := importer.New(tables, records).Import(context.TODO(), strings.NewReader(testCSV), importer.Options{
+			RecordRequest: xata.RecordRequest{TableName: "users"},
+			Format:        importer.FormatCSV,
+			TypeOverrides: map[string]xata.ColumnType{"age": xata.ColumnTypeInt},
+			CreateTable:   true,
+			BatchSize:     1,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, importer.Schema{
+			{Name: "name", Source: "name", Type: xata.ColumnTypeString},
+			{Name: "age", Source: "age", Type: xata.ColumnTypeInt},
+			{Name: "score", Source: "score", Type: xata.ColumnTypeFloat},
+			{Name: "active", Source: "active", Type: xata.ColumnTypeBool},
+			{Name: "joined", Source: "joined", Type: xata.ColumnTypeDatetime},
+			{Name: "tags", Source: "tags", Type: xata.ColumnTypeMultiple},
+		}, report.Schema)
+
+		assert.Len(t, report.Rejected, 1)
+		assert.Equal(t, 3, report.Rejected[0].Line)
+		assert.Equal(t, 2, report.Inserted)
+		assert.Len(t, records.batches, 2)
+		assert.Equal(t, []string{"users"}, tables.created)
+		assert.Len(t, tables.columns, 6)
+	})
+
+	t.Run("should apply the column mapping to NDJSON", func(t *testing.T) {
+		records := &fakeRecordsClient{}
+		data := "{\"id\":\"r1\",\"n\":1,\"skip\":true}\n\n{\"id\":\"r2\",\"n\":2.5}\nnot-json\n"
+
+		report, err := importer.New(nil, records).Import(context.TODO(), strings.NewReader(data), importer.Options{
+			RecordRequest: xata.RecordRequest{TableName: "items"},
+			Format:        importer.FormatNDJSON,
+			Mapping:       map[string]string{"n": "amount", "skip": ""},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, importer.Schema{
+			{Name: "id", Source: "id", Type: xata.ColumnTypeString},
+			{Name: "amount", Source: "n", Type: xata.ColumnTypeFloat},
+		}, report.Schema)
+		assert.Equal(t, 2, report.Inserted)
+		assert.Len(t, report.Rejected, 1)
+		assert.Equal(t, 4, report.Rejected[0].Line)
+		assert.Len(t, records.batches[0][0], 2)
+	})
+
+	t.Run("should not write in dry-run mode", func(t *testing.T) {
+		tables := &fakeTableClient{}
+		records := &fakeRecordsClient{}
+
+		report, err := importer.New(tables, records).Import(context.TODO(), strings.NewReader(testCSV), importer.Options{
+			RecordRequest: xata.RecordRequest{TableName: "users"},
+			Format:        importer.FormatCSV,
+			CreateTable:   true,
+			DryRun:        true,
+		})
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, xata.ColumnTypeString, report.Schema[1].Type)
+		assert.Contains(t, report.Schema.String(), "age\tstring")
+		assert.Empty(t, tables.created)
+		assert.Empty(t, records.batches)
+	})
+
+	t.Run("should return the partial report when inserting fails", func(t *testing.T) {
+		records := &fakeRecordsClient{err: errors.New("boom")}
+
+		report, err := importer.New(nil, records).Import(context.TODO(), strings.NewReader(testCSV), importer.Options{
+			RecordRequest: xata.RecordRequest{TableName: "users"},
+			Format:        importer.FormatCSV,
+		})
+		assert.ErrorContains(t, err, "boom")
+		assert.NotNil(t, report)
+		assert.Equal(t, 0, report.Inserted)
+	})
+
+	t.Run("should require a table name", func(t *testing.T) {
+		_, err := importer.New(nil, nil).Import(context.TODO(), strings.NewReader(testCSV), importer.Options{
+			Format: importer.FormatCSV,
+		})
+		assert.Error(t, err)
+	})
+}
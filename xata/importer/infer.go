@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// maxStringLength is the longest value that is still inferred as a `string` column.
+// Longer values, or values spanning multiple lines, are inferred as `text`.
+const maxStringLength = 2048
+
+// dateTimeLayouts are the layouts recognised when inferring `datetime` columns.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDateTime parses the value with the first matching layout in dateTimeLayouts.
+func parseDateTime(value string) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inferValue returns the narrowest column type for a single value and false if the value is null.
+func inferValue(value any) (xata.ColumnType, bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, false
+	case bool:
+		return xata.ColumnTypeBool, true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return xata.ColumnTypeInt, true
+		}
+		return xata.ColumnTypeFloat, true
+	case []any:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return xata.ColumnTypeJSON, true
+			}
+		}
+		return xata.ColumnTypeMultiple, true
+	case map[string]any:
+		return xata.ColumnTypeJSON, true
+	case string:
+		return inferString(v)
+	default:
+		return xata.ColumnTypeString, true
+	}
+}
+
+// inferString returns the narrowest column type for a textual value and false if it is empty.
+func inferString(value string) (xata.ColumnType, bool) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, false
+	}
+
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return xata.ColumnTypeInt, true
+	}
+
+	if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return xata.ColumnTypeFloat, true
+	}
+
+	switch strings.ToLower(trimmed) {
+	case "true", "false":
+		return xata.ColumnTypeBool, true
+	}
+
+	if _, ok := parseDateTime(trimmed); ok {
+		return xata.ColumnTypeDatetime, true
+	}
+
+	if strings.HasPrefix(trimmed, "[") {
+		var list []string
+		if err := json.Unmarshal([]byte(trimmed), &list); err == nil {
+			return xata.ColumnTypeMultiple, true
+		}
+	}
+
+	if len(value) > maxStringLength || strings.ContainsAny(value, "\r\n") {
+		return xata.ColumnTypeText, true
+	}
+
+	return xata.ColumnTypeString, true
+}
+
+// widen returns a column type able to hold values of both a and b.
+func widen(a, b xata.ColumnType) xata.ColumnType {
+	switch {
+	case a == 0:
+		return b
+	case b == 0, a == b:
+		return a
+	case isNumeric(a) && isNumeric(b):
+		return xata.ColumnTypeFloat
+	case a == xata.ColumnTypeText || b == xata.ColumnTypeText:
+		return xata.ColumnTypeText
+	default:
+		return xata.ColumnTypeString
+	}
+}
+
+func isNumeric(t xata.ColumnType) bool {
+	return t == xata.ColumnTypeInt || t == xata.ColumnTypeFloat
+}
+
+// convert turns a raw value into the input value for a column of the given type.
+func convert(value any, columnType xata.ColumnType) (*xata.DataInputRecordValue, error) {
+	switch columnType {
+	case xata.ColumnTypeInt:
+		n, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, err
+		}
+		return xata.ValueFromInteger(int(i)), nil
+	case xata.ColumnTypeFloat:
+		n, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return xata.ValueFromDouble(f), nil
+	case xata.ColumnTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return xata.ValueFromBoolean(v), nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, err
+			}
+			return xata.ValueFromBoolean(b), nil
+		}
+	case xata.ColumnTypeDatetime:
+		if v, ok := value.(string); ok {
+			t, ok := parseDateTime(strings.TrimSpace(v))
+			if ok {
+				return xata.ValueFromDateTime(t), nil
+			}
+		}
+	case xata.ColumnTypeMultiple:
+		switch v := value.(type) {
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected %T in multiple value", item)
+				}
+				list = append(list, s)
+			}
+			return xata.ValueFromStringList(list), nil
+		case string:
+			var list []string
+			if err := json.Unmarshal([]byte(strings.TrimSpace(v)), &list); err != nil {
+				return nil, err
+			}
+			return xata.ValueFromStringList(list), nil
+		}
+	case xata.ColumnTypeJSON:
+		if v, ok := value.(string); ok {
+			if !json.Valid([]byte(v)) {
+				return nil, fmt.Errorf("invalid JSON value %q", v)
+			}
+			return xata.ValueFromString(v), nil
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return xata.ValueFromString(string(raw)), nil
+	case xata.ColumnTypeString, xata.ColumnTypeText, xata.ColumnTypeEmail:
+		switch v := value.(type) {
+		case string:
+			return xata.ValueFromString(v), nil
+		case json.Number:
+			return xata.ValueFromString(v.String()), nil
+		case bool:
+			return xata.ValueFromString(strconv.FormatBool(v)), nil
+		default:
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return xata.ValueFromString(string(raw)), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported column type %s", columnTypeName(columnType))
+	}
+
+	return nil, fmt.Errorf("cannot convert %v to %s", value, columnTypeName(columnType))
+}
+
+func toNumber(value any) (json.Number, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v, nil
+	case string:
+		n := json.Number(strings.TrimSpace(v))
+		if _, err := n.Float64(); err != nil {
+			return "", err
+		}
+		return n, nil
+	}
+	return "", fmt.Errorf("cannot convert %v to a number", value)
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// maxLineSize is the longest NDJSON line that can be read.
+const maxLineSize = 16 * 1024 * 1024
+
+// row is a single record read from the source, keyed by source field.
+type row struct {
+	line   int
+	values map[string]any
+	err    error
+}
+
+// read returns the source fields in order of appearance and the rows.
+// Malformed rows are returned with err set so that they can be reported.
+func read(r io.Reader, format Format) ([]string, []row, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatNDJSON:
+		return readNDJSON(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format: %d", format)
+	}
+}
+
+func readCSV(r io.Reader) ([]string, []row, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("missing CSV header")
+		}
+		return nil, nil, err
+	}
+
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, row{line: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]any, len(header))
+		for i, field := range header {
+			values[field] = record[i]
+		}
+		rows = append(rows, row{line: line, values: values})
+	}
+
+	return header, rows, nil
+}
+
+func readNDJSON(r io.Reader) ([]string, []row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var (
+		fields []string
+		rows   []row
+		seen   = make(map[string]bool)
+		line   int
+	)
+	for scanner.Scan() {
+		line++
+
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+
+		var values map[string]any
+		if err := decoder.Decode(&values); err != nil {
+			rows = append(rows, row{line: line, err: err})
+			continue
+		}
+
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			if !seen[k] {
+				keys = append(keys, k)
+				seen[k] = true
+			}
+		}
+		sort.Strings(keys)
+		fields = append(fields, keys...)
+
+		rows = append(rows, row{line: line, values: values})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return fields, rows, nil
+}
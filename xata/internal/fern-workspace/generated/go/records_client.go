@@ -23,7 +23,7 @@ type RecordsClient interface {
 	UpsertRecordWithId(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId, request *UpsertRecordWithIdRequest) (*UpsertRecordWithIdResponse, error)
 	InsertRecordWithId(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId, request *InsertRecordWithIdRequest) (*InsertRecordWithIdResponse, error)
 	UpdateRecordWithId(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId, request *UpdateRecordWithIdRequest) (*UpdateRecordWithIdResponse, error)
-	DeleteRecord(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId) error
+	DeleteRecord(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId, request *DeleteRecordRequest) (*Record, error)
 	BulkInsertTableRecords(ctx context.Context, dbBranchName DbBranchName, tableName TableName, request *BulkInsertTableRecordsRequest) (*BulkInsertTableRecordsResponse, error)
 }
 
@@ -497,7 +497,7 @@ func (r *recordsClient) UpdateRecordWithId(ctx context.Context, dbBranchName DbB
 //
 // The Table name
 // The Record name
-func (r *recordsClient) DeleteRecord(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId) error {
+func (r *recordsClient) DeleteRecord(ctx context.Context, dbBranchName DbBranchName, tableName TableName, recordId RecordId, request *DeleteRecordRequest) (*Record, error) {
 	baseURL := "/"
 	if r.baseURL != "" {
 		baseURL = r.baseURL
@@ -505,6 +505,9 @@ func (r *recordsClient) DeleteRecord(ctx context.Context, dbBranchName DbBranchN
 	endpointURL := fmt.Sprintf(baseURL+"/"+"db/%v/tables/%v/data/%v", dbBranchName, tableName, recordId)
 
 	queryParams := make(url.Values)
+	for _, value := range request.Columns {
+		queryParams.Add("columns", fmt.Sprintf("%v", *value))
+	}
 	if len(queryParams) > 0 {
 		endpointURL += "?" + queryParams.Encode()
 	}
@@ -542,21 +545,21 @@ func (r *recordsClient) DeleteRecord(ctx context.Context, dbBranchName DbBranchN
 		return apiError
 	}
 
-	//var response *Record
+	var response *Record
 	if err := core.DoRequest(
 		ctx,
 		r.httpClient,
 		endpointURL,
 		http.MethodDelete,
 		nil,
-		nil,
-		false,
+		&response,
+		true,
 		r.header,
 		errorDecoder,
 	); err != nil {
-		return err
+		return response, err
 	}
-	return nil
+	return response, nil
 }
 
 // Bulk insert records
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+// Role is the role of a member in a workspace.
+type Role xatagencore.Role
+
+const (
+	RoleOwner Role = iota + 1
+	RoleMaintainer
+)
+
+func (r Role) String() string {
+	return xatagencore.Role(r).String()
+}
+
+func (r Role) validate() error {
+	if r != RoleOwner && r != RoleMaintainer {
+		return fmt.Errorf("invalid role: %d", r)
+	}
+	return nil
+}
+
+type InviteWorkspaceMemberRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	Email       string
+	Role        Role
+}
+
+type UpdateWorkspaceMemberInviteRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	InviteID    string
+	Role        Role
+}
+
+type WorkspaceMemberInviteRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	InviteID    string
+}
+
+type AcceptWorkspaceMemberInviteRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	InviteKey   string
+}
+
+type InvitesClient interface {
+	Invite(ctx context.Context, request InviteWorkspaceMemberRequest) (*xatagencore.WorkspaceInvite, error)
+	Update(ctx context.Context, request UpdateWorkspaceMemberInviteRequest) (*xatagencore.WorkspaceInvite, error)
+	Cancel(ctx context.Context, request WorkspaceMemberInviteRequest) error
+	Resend(ctx context.Context, request WorkspaceMemberInviteRequest) error
+	Accept(ctx context.Context, request AcceptWorkspaceMemberInviteRequest) error
+}
+
+type invitesCli struct {
+	generated   xatagencore.InvitesClient
+	workspaceID string
+}
+
+// Invite invites a user to join the workspace with the given role.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/invites#invite-a-user-to-join-the-workspace
+func (i invitesCli) Invite(ctx context.Context, request InviteWorkspaceMemberRequest) (*xatagencore.WorkspaceInvite, error) {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, i.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(request.Email, "@") {
+		return nil, fmt.Errorf("invalid email: %q", request.Email)
+	}
+	if err := request.Role.validate(); err != nil {
+		return nil, err
+	}
+
+	return i.generated.InviteWorkspaceMember(ctx, workspaceID, &xatagencore.InviteWorkspaceMemberRequest{
+		Email: request.Email,
+		Role:  xatagencore.Role(request.Role),
+	})
+}
+
+// Update changes the role of a pending invite.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/invites/invite_id#updates-an-existing-invite
+func (i invitesCli) Update(ctx context.Context, request UpdateWorkspaceMemberInviteRequest) (*xatagencore.WorkspaceInvite, error) {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, i.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if request.InviteID == "" {
+		return nil, fmt.Errorf("invite ID cannot be empty")
+	}
+	if err := request.Role.validate(); err != nil {
+		return nil, err
+	}
+
+	return i.generated.UpdateWorkspaceMemberInvite(ctx, workspaceID, request.InviteID, &xatagencore.UpdateWorkspaceMemberInviteRequest{
+		Role: xatagencore.Role(request.Role),
+	})
+}
+
+// Cancel deletes a pending invite.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/invites/invite_id#deletes-an-invite
+func (i invitesCli) Cancel(ctx context.Context, request WorkspaceMemberInviteRequest) error {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, i.workspaceID)
+	if err != nil {
+		return err
+	}
+	if request.InviteID == "" {
+		return fmt.Errorf("invite ID cannot be empty")
+	}
+
+	return i.generated.CancelWorkspaceMemberInvite(ctx, workspaceID, request.InviteID)
+}
+
+// Resend sends the invite email again.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/invites/invite_id/resend#resend-invite-notification
+func (i invitesCli) Resend(ctx context.Context, request WorkspaceMemberInviteRequest) error {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, i.workspaceID)
+	if err != nil {
+		return err
+	}
+	if request.InviteID == "" {
+		return fmt.Errorf("invite ID cannot be empty")
+	}
+
+	return i.generated.ResendWorkspaceMemberInvite(ctx, workspaceID, request.InviteID)
+}
+
+// Accept accepts an invite on behalf of the user of the API key, using the key from the
+// invite email.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/invites/invite_key/accept#accept-the-invitation-to-join-a-workspace
+func (i invitesCli) Accept(ctx context.Context, request AcceptWorkspaceMemberInviteRequest) error {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, i.workspaceID)
+	if err != nil {
+		return err
+	}
+	if request.InviteKey == "" {
+		return fmt.Errorf("invite key cannot be empty")
+	}
+
+	return i.generated.AcceptWorkspaceMemberInvite(ctx, workspaceID, request.InviteKey)
+}
+
+// NewInvitesClient constructs a client for managing workspace invites.
+func NewInvitesClient(opts ...ClientOption) (InvitesClient, error) {
+	cliOpts, err := consolidateClientOptionsForCore(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCfg, err := loadDatabaseConfig(cliOpts)
+	if err != nil {
+		// No err, because the workspace ID can be provided in the requests.
+		log.Println(err)
+	}
+
+	return invitesCli{
+		generated: xatagencore.NewInvitesClient(
+			func(options *xatagenclient.ClientOptions) {
+				options.HTTPClient = cliOpts.HTTPClient
+				options.BaseURL = cliOpts.BaseURL
+				options.Bearer = cliOpts.Bearer
+			}),
+		workspaceID: dbCfg.workspaceID,
+	}, nil
+}
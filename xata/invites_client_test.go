@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+	xatagen "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+func TestNewInvitesClient(t *testing.T) {
+	t.Run("should construct a new invites client", func(t *testing.T) {
+		got, err := xata.NewInvitesClient(xata.WithAPIKey("my-api-token"))
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+}
+
+func Test_invitesClient_Invite(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagen.WorkspaceInvite
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should invite a member",
+			want: &xatagen.WorkspaceInvite{
+				InviteId: "invite-id",
+				Email:    "jane@example.com",
+				Expires:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Role:     xatagen.RoleMaintainer,
+			},
+			statusCode: http.StatusCreated,
+		},
+	}
+
+	for _, eTC := range errTestCasesCore {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodPost, "/workspaces/ws-id/invites", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewInvitesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+			assert.NoError(err)
+
+			got, err := cli.Invite(context.TODO(), xata.InviteWorkspaceMemberRequest{
+				Email: "jane@example.com",
+				Role:  xata.RoleMaintainer,
+			})
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_invitesClient_Update(t *testing.T) {
+	want := &xatagen.WorkspaceInvite{InviteId: "invite-id", Role: xatagen.RoleOwner}
+	testSrv := testService(t, http.MethodPatch, "/workspaces/other-ws/invites/invite-id", http.StatusOK, false, want)
+	defer testSrv.Close()
+
+	cli, err := xata.NewInvitesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+	assert.NoError(t, err)
+
+	got, err := cli.Update(context.TODO(), xata.UpdateWorkspaceMemberInviteRequest{
+		WorkspaceID: xata.String("other-ws"),
+		InviteID:    "invite-id",
+		Role:        xata.RoleOwner,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_invitesClient_CancelResendAccept(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		call   func(cli xata.InvitesClient) error
+	}{
+		{
+			name:   "should cancel an invite",
+			method: http.MethodDelete,
+			path:   "/workspaces/ws-id/invites/invite-id",
+			call: func(cli xata.InvitesClient) error {
+				return cli.Cancel(context.TODO(), xata.WorkspaceMemberInviteRequest{InviteID: "invite-id"})
+			},
+		},
+		{
+			name:   "should resend an invite",
+			method: http.MethodPost,
+			path:   "/workspaces/ws-id/invites/invite-id/resend",
+			call: func(cli xata.InvitesClient) error {
+				return cli.Resend(context.TODO(), xata.WorkspaceMemberInviteRequest{InviteID: "invite-id"})
+			},
+		},
+		{
+			name:   "should accept an invite",
+			method: http.MethodPost,
+			path:   "/workspaces/ws-id/invites/invite-key/accept",
+			call: func(cli xata.InvitesClient) error {
+				return cli.Accept(context.TODO(), xata.AcceptWorkspaceMemberInviteRequest{InviteKey: "invite-key"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, tt.method, tt.path, http.StatusNoContent, false, nil)
+			defer testSrv.Close()
+
+			cli, err := xata.NewInvitesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+			assert.NoError(err)
+			assert.NoError(tt.call(cli))
+		})
+	}
+}
+
+func Test_invitesClient_validation(t *testing.T) {
+	cli, err := xata.NewInvitesClient(xata.WithBaseURL("http://localhost"), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+	assert.NoError(t, err)
+
+	_, err = cli.Invite(context.TODO(), xata.InviteWorkspaceMemberRequest{Email: "jane", Role: xata.RoleOwner})
+	assert.Error(t, err)
+
+	_, err = cli.Invite(context.TODO(), xata.InviteWorkspaceMemberRequest{Email: "jane@example.com"})
+	assert.EqualError(t, err, "invalid role: 0")
+
+	_, err = cli.Update(context.TODO(), xata.UpdateWorkspaceMemberInviteRequest{Role: xata.RoleOwner})
+	assert.EqualError(t, err, "invite ID cannot be empty")
+
+	assert.Error(t, cli.Cancel(context.TODO(), xata.WorkspaceMemberInviteRequest{}))
+	assert.Error(t, cli.Resend(context.TODO(), xata.WorkspaceMemberInviteRequest{}))
+	assert.Error(t, cli.Accept(context.TODO(), xata.AcceptWorkspaceMemberInviteRequest{}))
+
+	assert.Equal(t, "maintainer", xata.RoleMaintainer.String())
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+type UpdateOAuthAccessTokenRequest struct {
+	Token   string
+	Expires time.Time
+}
+
+// OAuthClient manages the OAuth clients the user authorized, and their access tokens.
+type OAuthClient interface {
+	ListClients(ctx context.Context) (*xatagencore.GetUserOAuthClientsResponse, error)
+	DeleteClient(ctx context.Context, clientID string) error
+	ListAccessTokens(ctx context.Context) (*xatagencore.GetUserOAuthAccessTokensResponse, error)
+	DeleteAccessToken(ctx context.Context, token string) error
+	UpdateAccessToken(ctx context.Context, request UpdateOAuthAccessTokenRequest) (*xatagencore.OAuthAccessToken, error)
+}
+
+type oauthCli struct {
+	generated xatagencore.OAuthClient
+}
+
+// ListClients retrieves the OAuth clients the user authorized.
+// https://xata.io/docs/api-reference/user/oauth/clients#get-the-list-of-user-oauth-clients
+func (o oauthCli) ListClients(ctx context.Context) (*xatagencore.GetUserOAuthClientsResponse, error) {
+	return o.generated.GetUserOAuthClients(ctx)
+}
+
+// DeleteClient revokes an OAuth client for the user.
+// https://xata.io/docs/api-reference/user/oauth/clients/client_id#delete-the-oauth-client-for-the-user
+func (o oauthCli) DeleteClient(ctx context.Context, clientID string) error {
+	if clientID == "" {
+		return fmt.Errorf("client ID cannot be empty")
+	}
+
+	return o.generated.DeleteUserOAuthClient(ctx, clientID)
+}
+
+// ListAccessTokens retrieves the OAuth access tokens issued to third party apps for the user.
+// https://xata.io/docs/api-reference/user/oauth/tokens#get-the-list-of-user-oauth-access-tokens
+func (o oauthCli) ListAccessTokens(ctx context.Context) (*xatagencore.GetUserOAuthAccessTokensResponse, error) {
+	return o.generated.GetUserOAuthAccessTokens(ctx)
+}
+
+// DeleteAccessToken revokes an OAuth access token.
+// https://xata.io/docs/api-reference/user/oauth/tokens/token#delete-an-access-token-for-a-third-party-app
+func (o oauthCli) DeleteAccessToken(ctx context.Context, token string) error {
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+
+	return o.generated.DeleteOAuthAccessToken(ctx, token)
+}
+
+// UpdateAccessToken changes the expiration time of an OAuth access token.
+// https://xata.io/docs/api-reference/user/oauth/tokens/token#updates-an-access-token-for-a-third-party-app
+func (o oauthCli) UpdateAccessToken(ctx context.Context, request UpdateOAuthAccessTokenRequest) (*xatagencore.OAuthAccessToken, error) {
+	if request.Token == "" {
+		return nil, fmt.Errorf("token cannot be empty")
+	}
+	if request.Expires.IsZero() {
+		return nil, fmt.Errorf("expiration time cannot be empty")
+	}
+
+	return o.generated.UpdateOAuthAccessToken(ctx, request.Token, &xatagencore.UpdateOAuthAccessTokenRequest{
+		Expires: int(request.Expires.Unix()),
+	})
+}
+
+// NewOAuthClient constructs a client for managing OAuth clients and access tokens.
+func NewOAuthClient(opts ...ClientOption) (OAuthClient, error) {
+	cliOpts, err := consolidateClientOptionsForCore(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthCli{
+		generated: xatagencore.NewOAuthClient(
+			func(options *xatagenclient.ClientOptions) {
+				options.HTTPClient = cliOpts.HTTPClient
+				options.BaseURL = cliOpts.BaseURL
+				options.Bearer = cliOpts.Bearer
+			}),
+	}, nil
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+	xatagen "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
+	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
+)
+
+func TestNewOAuthClient(t *testing.T) {
+	t.Run("should construct a new OAuth client", func(t *testing.T) {
+		got, err := xata.NewOAuthClient(xata.WithAPIKey("my-api-token"))
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+}
+
+func Test_oauthClient_ListAccessTokens(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagen.GetUserOAuthAccessTokensResponse
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should list the access tokens",
+			want: &xatagen.GetUserOAuthAccessTokensResponse{
+				AccessTokens: []*xatagen.OAuthAccessToken{
+					{
+						Token:     "token",
+						Scopes:    []string{"admin:all"},
+						CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+						UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+						ExpiresAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+						ClientId:  "client-id",
+					},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, eTC := range errTestCasesCore {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodGet, "/user/oauth/tokens", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewOAuthClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+			assert.NoError(err)
+
+			got, err := cli.ListAccessTokens(context.TODO())
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_oauthClient_ListClients(t *testing.T) {
+	want := &xatagen.GetUserOAuthClientsResponse{
+		Clients: &[]*xatagen.OAuthClientPublicDetails{{ClientId: "client-id", Name: xata.String("My app")}},
+	}
+	testSrv := testService(t, http.MethodGet, "/user/oauth/clients", http.StatusOK, false, want)
+	defer testSrv.Close()
+
+	cli, err := xata.NewOAuthClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.ListClients(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_oauthClient_Delete(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		call func(cli xata.OAuthClient) error
+	}{
+		{
+			name: "should delete a client",
+			path: "/user/oauth/clients/client-id",
+			call: func(cli xata.OAuthClient) error { return cli.DeleteClient(context.TODO(), "client-id") },
+		},
+		{
+			name: "should delete an access token",
+			path: "/user/oauth/tokens/token",
+			call: func(cli xata.OAuthClient) error { return cli.DeleteAccessToken(context.TODO(), "token") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodDelete, tt.path, http.StatusNoContent, false, nil)
+			defer testSrv.Close()
+
+			cli, err := xata.NewOAuthClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+			assert.NoError(t, err)
+			assert.NoError(t, tt.call(cli))
+		})
+	}
+}
+
+func Test_oauthClient_UpdateAccessToken(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/user/oauth/tokens/token", r.URL.Path)
+
+		var body map[string]int64
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, expires.Unix(), body["expires"])
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"token": "token", "expiresAt": expires})
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewOAuthClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.UpdateAccessToken(context.TODO(), xata.UpdateOAuthAccessTokenRequest{Token: "token", Expires: expires})
+	assert.NoError(t, err)
+	assert.Equal(t, expires, got.ExpiresAt)
+
+	_, err = cli.UpdateAccessToken(context.TODO(), xata.UpdateOAuthAccessTokenRequest{Token: "token"})
+	assert.EqualError(t, err, "expiration time cannot be empty")
+	assert.EqualError(t, cli.DeleteClient(context.TODO(), ""), "client ID cannot be empty")
+}
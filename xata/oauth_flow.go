@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuthScope is a permission requested by an OAuth app.
+type OAuthScope string
+
+// OAuthScopeAdminAll grants full access on behalf of the user.
+const OAuthScopeAdminAll OAuthScope = "admin:all"
+
+// OAuthConfig describes an OAuth app acting on behalf of Xata users with the authorization
+// code flow.
+type OAuthConfig struct {
+	ClientID string
+	// ClientSecret is left empty for public clients, which rely on PKCE only.
+	ClientSecret string
+	RedirectURL  string
+	// AuthURL and TokenURL are the authorization and token endpoints given with the client
+	// credentials.
+	AuthURL  string
+	TokenURL string
+	// Scopes default to admin:all.
+	Scopes []OAuthScope
+	// HTTPClient is used for the token exchange. http.DefaultClient by default.
+	HTTPClient httpClient
+}
+
+// PKCE holds a proof key for code exchange. The verifier must be kept by the app between the
+// redirect to the authorization URL and the code exchange.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// NewPKCE generates a random verifier and its S256 challenge.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := randomURLSafe(32)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// NewOAuthState generates a random state to protect the redirect against request forgery.
+func NewOAuthState() (string, error) {
+	return randomURLSafe(16)
+}
+
+func randomURLSafe(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OAuthToken is the result of a code exchange.
+type OAuthToken struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	// Expiry is zero when the token server didn't return a lifetime.
+	Expiry time.Time
+	Scopes []OAuthScope
+}
+
+// Valid reports whether the token is set and not expired.
+func (t *OAuthToken) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Before(t.Expiry))
+}
+
+// AuthCodeURL returns the URL to redirect the user to, for them to authorize the app.
+func (c OAuthConfig) AuthCodeURL(state string, pkce *PKCE) (string, error) {
+	if err := c.validate(); err != nil {
+		return "", err
+	}
+	if state == "" {
+		return "", fmt.Errorf("state cannot be empty")
+	}
+	if pkce == nil {
+		return "", fmt.Errorf("PKCE cannot be nil")
+	}
+	if c.AuthURL == "" {
+		return "", fmt.Errorf("authorization URL cannot be empty")
+	}
+
+	u, err := url.Parse(c.AuthURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization URL: %w", err)
+	}
+
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = []OAuthScope{OAuthScopeAdminAll}
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", c.RedirectURL)
+	q.Set("scope", joinScopes(scopes))
+	q.Set("state", state)
+	q.Set("code_challenge", pkce.Challenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange trades the authorization code received on the redirect URL for an access token.
+func (c OAuthConfig) Exchange(ctx context.Context, code string, pkce *PKCE) (*OAuthToken, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, fmt.Errorf("code cannot be empty")
+	}
+	if pkce == nil {
+		return nil, fmt.Errorf("PKCE cannot be nil")
+	}
+
+	return c.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.RedirectURL},
+		"code_verifier": {pkce.Verifier},
+	})
+}
+
+// Refresh trades a refresh token for a new access token.
+func (c OAuthConfig) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	if c.ClientID == "" {
+		return nil, fmt.Errorf("client ID cannot be empty")
+	}
+	if refreshToken == "" {
+		return nil, fmt.Errorf("refresh token cannot be empty")
+	}
+
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// token requests a token from the token endpoint.
+func (c OAuthConfig) token(ctx context.Context, form url.Values) (*OAuthToken, error) {
+	if c.TokenURL == "" {
+		return nil, fmt.Errorf("token URL cannot be empty")
+	}
+
+	form.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	cli := c.HTTPClient
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Scope            string `json:"scope"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil && resp.StatusCode < 300 {
+		return nil, fmt.Errorf("unable to decode the token response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 || payload.Error != "" {
+		if payload.Error != "" {
+			return nil, fmt.Errorf("token request failed with status %d: %s %s", resp.StatusCode, payload.Error, payload.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body)
+	}
+	if payload.AccessToken == "" {
+		return nil, fmt.Errorf("token response without access token")
+	}
+
+	token := &OAuthToken{
+		AccessToken:  payload.AccessToken,
+		TokenType:    payload.TokenType,
+		RefreshToken: payload.RefreshToken,
+		Scopes:       splitScopes(payload.Scope),
+	}
+	if payload.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(payload.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+func (c OAuthConfig) validate() error {
+	if c.ClientID == "" {
+		return fmt.Errorf("client ID cannot be empty")
+	}
+	if c.RedirectURL == "" {
+		return fmt.Errorf("redirect URL cannot be empty")
+	}
+	return nil
+}
+
+// joinScopes formats scopes as the space separated scope parameter.
+func joinScopes(scopes []OAuthScope) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return strings.Join(s, " ")
+}
+
+// splitScopes parses a space separated scope parameter.
+func splitScopes(scope string) []OAuthScope {
+	var scopes []OAuthScope
+	for _, s := range strings.Fields(scope) {
+		scopes = append(scopes, OAuthScope(s))
+	}
+	return scopes
+}
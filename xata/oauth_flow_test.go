@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := xata.NewPKCE()
+	assert.NoError(t, err)
+	assert.Len(t, pkce.Verifier, 43)
+
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), pkce.Challenge)
+
+	other, err := xata.NewPKCE()
+	assert.NoError(t, err)
+	assert.NotEqual(t, pkce.Verifier, other.Verifier)
+}
+
+func TestOAuthConfig_AuthCodeURL(t *testing.T) {
+	cfg := xata.OAuthConfig{
+		ClientID:    "client-id",
+		RedirectURL: "http://localhost:8080/callback",
+		AuthURL:     "https://example.com/oauth/authorize?prompt=consent",
+	}
+	pkce := &xata.PKCE{Verifier: "verifier", Challenge: "challenge"}
+
+	got, err := cfg.AuthCodeURL("state", pkce)
+	assert.NoError(t, err)
+
+	u, err := url.Parse(got)
+	assert.NoError(t, err)
+	assert.Equal(t, "/oauth/authorize", u.Path)
+	assert.Equal(t, url.Values{
+		"prompt":                {"consent"},
+		"response_type":         {"code"},
+		"client_id":             {"client-id"},
+		"redirect_uri":          {"http://localhost:8080/callback"},
+		"scope":                 {"admin:all"},
+		"state":                 {"state"},
+		"code_challenge":        {"challenge"},
+		"code_challenge_method": {"S256"},
+	}, u.Query())
+
+	cfg.Scopes = []xata.OAuthScope{xata.OAuthScopeAdminAll, "read:all"}
+	got, err = cfg.AuthCodeURL("state", pkce)
+	assert.NoError(t, err)
+	u, err = url.Parse(got)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin:all read:all", u.Query().Get("scope"))
+
+	_, err = cfg.AuthCodeURL("", pkce)
+	assert.EqualError(t, err, "state cannot be empty")
+	_, err = cfg.AuthCodeURL("state", nil)
+	assert.Error(t, err)
+
+	cfg.ClientID = ""
+	_, err = cfg.AuthCodeURL("state", pkce)
+	assert.EqualError(t, err, "client ID cannot be empty")
+}
+
+func TestOAuthConfig_Exchange(t *testing.T) {
+	pkce := &xata.PKCE{Verifier: "verifier", Challenge: "challenge"}
+
+	newConfig := func(t *testing.T, handler http.HandlerFunc) xata.OAuthConfig {
+		testSrv := httptest.NewServer(handler)
+		t.Cleanup(testSrv.Close)
+
+		return xata.OAuthConfig{
+			ClientID:    "client-id",
+			RedirectURL: "http://localhost:8080/callback",
+			AuthURL:     testSrv.URL + "/authorize",
+			TokenURL:    testSrv.URL + "/token",
+		}
+	}
+
+	t.Run("should exchange the code for a token", func(t *testing.T) {
+		cfg := newConfig(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "the-code", r.PostForm.Get("code"))
+			assert.Equal(t, "verifier", r.PostForm.Get("code_verifier"))
+			assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+			assert.False(t, r.PostForm.Has("client_secret"))
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "access", "token_type": "Bearer", "expires_in": 3600, "scope": "admin:all",
+			})
+		})
+
+		got, err := cfg.Exchange(context.TODO(), "the-code", pkce)
+		assert.NoError(t, err)
+		assert.Equal(t, "access", got.AccessToken)
+		assert.Equal(t, []xata.OAuthScope{xata.OAuthScopeAdminAll}, got.Scopes)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), got.Expiry, time.Minute)
+		assert.True(t, got.Valid())
+
+		opts := &xata.ClientOptions{}
+		xata.WithOAuthToken(got)(opts)
+		assert.Equal(t, "access", opts.Bearer)
+	})
+
+	t.Run("should return the token endpoint error", func(t *testing.T) {
+		cfg := newConfig(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code expired"})
+		})
+
+		_, err := cfg.Exchange(context.TODO(), "the-code", pkce)
+		assert.EqualError(t, err, "token request failed with status 400: invalid_grant code expired")
+	})
+
+	t.Run("should validate the exchange", func(t *testing.T) {
+		cfg := newConfig(t, func(http.ResponseWriter, *http.Request) { t.Fatal("unexpected request") })
+
+		_, err := cfg.Exchange(context.TODO(), "", pkce)
+		assert.EqualError(t, err, "code cannot be empty")
+		_, err = cfg.Exchange(context.TODO(), "the-code", nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxQueryPageSize is the maximum number of records returned by a query.
+	maxQueryPageSize = 200
+	// maxQueryOffset is the maximum number of records a query can skip.
+	maxQueryOffset = 800
+	// scoreColumn is the column holding the relevance score of the records.
+	scoreColumn = "xata.score"
+)
+
+// QueryBuilder builds a QueryTableRequest.
+//
+//	request, err := xata.From("users").
+//		Select("name", "email").
+//		Expand("team", "name").
+//		Where(filter).
+//		OrderBy("createdAt", xata.SortOrderDesc).
+//		Limit(50).
+//		Strong().
+//		Build()
+type QueryBuilder struct {
+	request QueryTableRequest
+	filters []*FilterExpression
+	sort    []map[string]SortOrder
+	random  bool
+	score   bool
+	limit   *int
+	offset  *int
+	cursor  queryCursor
+}
+
+// queryCursor holds the cursors of a query, set by After, Before, Start and End.
+type queryCursor struct {
+	after, before, start, end *string
+}
+
+func (c queryCursor) isSet() bool {
+	return c.after != nil || c.before != nil || c.start != nil || c.end != nil
+}
+
+// From starts building a query of the given table.
+func From(tableName string) *QueryBuilder {
+	return &QueryBuilder{request: QueryTableRequest{TableName: tableName}}
+}
+
+// Database sets the database, the database of the client is used otherwise.
+func (q *QueryBuilder) Database(name string) *QueryBuilder {
+	q.request.DatabaseName = String(name)
+	return q
+}
+
+// Branch sets the branch, the branch of the client is used otherwise.
+func (q *QueryBuilder) Branch(name string) *QueryBuilder {
+	q.request.BranchName = String(name)
+	return q
+}
+
+// Select adds columns to return. Link columns can be expanded with a dot, for example:
+// `owner.name` or `owner.*`.
+func (q *QueryBuilder) Select(columns ...string) *QueryBuilder {
+	q.request.Payload.Columns = append(q.request.Payload.Columns, columns...)
+	return q
+}
+
+// Expand returns the given columns of the records referenced by a link column, or all of their
+// columns if none is given.
+func (q *QueryBuilder) Expand(link string, columns ...string) *QueryBuilder {
+	if len(columns) == 0 {
+		return q.Select(link + ".*")
+	}
+
+	for _, c := range columns {
+		q.Select(link + "." + c)
+	}
+	return q
+}
+
+// Where only returns the records matching the filter. Multiple filters must all match.
+func (q *QueryBuilder) Where(filter *FilterExpression) *QueryBuilder {
+	q.filters = append(q.filters, filter)
+	return q
+}
+
+// OrderBy sorts the records by a column. The sort orders are applied in the order they are added.
+func (q *QueryBuilder) OrderBy(column string, order SortOrder) *QueryBuilder {
+	q.sort = append(q.sort, map[string]SortOrder{column: order})
+	return q
+}
+
+// OrderByRandom sorts the records randomly. It must be the last sort order, and is used to break
+// the ties of the previous ones.
+func (q *QueryBuilder) OrderByRandom() *QueryBuilder {
+	q.random = true
+	q.sort = append(q.sort, map[string]SortOrder{"*": SortOrderRandom})
+	return q
+}
+
+// OrderByScore sorts the records by relevance score, highest first.
+func (q *QueryBuilder) OrderByScore() *QueryBuilder {
+	q.score = true
+	q.sort = append(q.sort, map[string]SortOrder{scoreColumn: SortOrderDesc})
+	return q
+}
+
+// Limit sets the maximum number of records returned, between 1 and 200.
+func (q *QueryBuilder) Limit(size int) *QueryBuilder {
+	q.limit = &size
+	return q
+}
+
+// Offset skips the given number of records, at most 800.
+func (q *QueryBuilder) Offset(offset int) *QueryBuilder {
+	q.offset = &offset
+	return q
+}
+
+// After returns the page following the cursor of a previous query. The filter and sort are
+// encoded in the cursor, so they cannot be set.
+func (q *QueryBuilder) After(cursor string) *QueryBuilder {
+	q.cursor.after = &cursor
+	return q
+}
+
+// Before returns the page preceding the cursor of a previous query. Combined with After, it
+// returns the records between both cursors.
+func (q *QueryBuilder) Before(cursor string) *QueryBuilder {
+	q.cursor.before = &cursor
+	return q
+}
+
+// Start returns the first page of the query of the cursor.
+func (q *QueryBuilder) Start(cursor string) *QueryBuilder {
+	q.cursor.start = &cursor
+	return q
+}
+
+// End returns the last page of the query of the cursor.
+func (q *QueryBuilder) End(cursor string) *QueryBuilder {
+	q.cursor.end = &cursor
+	return q
+}
+
+// Strong reads the records from the primary, so that the latest writes are returned.
+func (q *QueryBuilder) Strong() *QueryBuilder {
+	q.request.Payload.Consistency = ConsistencyStrong
+	return q
+}
+
+// Eventual reads the records from a replica, which may lag behind the latest writes.
+func (q *QueryBuilder) Eventual() *QueryBuilder {
+	q.request.Payload.Consistency = ConsistencyEventual
+	return q
+}
+
+// Build validates and returns the request.
+func (q *QueryBuilder) Build() (QueryTableRequest, error) {
+	request := q.request
+
+	if request.TableName == "" {
+		return QueryTableRequest{}, fmt.Errorf("table name cannot be empty")
+	}
+
+	for _, c := range request.Payload.Columns {
+		if c == "" || strings.HasPrefix(c, ".") || strings.HasSuffix(c, ".") {
+			return QueryTableRequest{}, fmt.Errorf("invalid column %q", c)
+		}
+	}
+
+	for _, f := range q.filters {
+		if f == nil {
+			return QueryTableRequest{}, fmt.Errorf("filter cannot be nil")
+		}
+	}
+
+	if err := q.validateSort(); err != nil {
+		return QueryTableRequest{}, err
+	}
+	if err := q.validatePage(); err != nil {
+		return QueryTableRequest{}, err
+	}
+
+	switch len(q.filters) {
+	case 0:
+	case 1:
+		request.Payload.Filter = q.filters[0]
+	default:
+		request.Payload.Filter = &FilterExpression{All: NewFilterListFromFilterExpressionList(q.filters)}
+	}
+
+	if len(q.sort) > 0 {
+		request.Payload.Sort = NewSortExpressionFromStringSortOrderMapList(q.sort)
+	}
+
+	if q.limit != nil || q.offset != nil || q.cursor.isSet() {
+		request.Payload.Page = &PageConfig{
+			Size:   q.limit,
+			Offset: q.offset,
+			After:  q.cursor.after,
+			Before: q.cursor.before,
+			Start:  q.cursor.start,
+			End:    q.cursor.end,
+		}
+	}
+
+	return request, nil
+}
+
+func (q *QueryBuilder) validateSort() error {
+	if q.random && q.score {
+		return fmt.Errorf("cannot sort both randomly and by score")
+	}
+
+	for i, s := range q.sort {
+		for column, order := range s {
+			switch {
+			case column == "":
+				return fmt.Errorf("sort column cannot be empty")
+			case order == SortOrderRandom && column != "*":
+				return fmt.Errorf("cannot sort %s randomly, use OrderByRandom", column)
+			case column == "*" && order != SortOrderRandom:
+				return fmt.Errorf("cannot sort all columns, use OrderByRandom")
+			case order < SortOrderAsc || order > SortOrderRandom:
+				return fmt.Errorf("invalid sort order %d for %s", order, column)
+			case column == "*" && i != len(q.sort)-1:
+				return fmt.Errorf("random sort must be the last sort order")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (q *QueryBuilder) validatePage() error {
+	if q.limit != nil && (*q.limit < 1 || *q.limit > maxQueryPageSize) {
+		return fmt.Errorf("limit must be between 1 and %d", maxQueryPageSize)
+	}
+	if q.offset != nil && (*q.offset < 0 || *q.offset > maxQueryOffset) {
+		return fmt.Errorf("offset must be between 0 and %d", maxQueryOffset)
+	}
+
+	c := q.cursor
+	if !c.isSet() {
+		return nil
+	}
+
+	if (c.start != nil || c.end != nil) && (c.after != nil || c.before != nil || (c.start != nil && c.end != nil)) {
+		return fmt.Errorf("start and end cannot be combined with another cursor")
+	}
+	if len(q.filters) > 0 || len(q.sort) > 0 {
+		return fmt.Errorf("filter and sort cannot be set with a cursor, they are encoded in it")
+	}
+
+	return nil
+}
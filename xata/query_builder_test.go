@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+)
+
+func TestQueryBuilder_Build(t *testing.T) {
+	isActive := &xata.FilterExpression{Exists: xata.String("active")}
+	hasEmail := &xata.FilterExpression{Exists: xata.String("email")}
+
+	t.Run("should build a query request", func(t *testing.T) {
+		request, err := xata.From("users").
+			Database("my-db").
+			Branch("dev").
+			Select("name").
+			Expand("owner").
+			Expand("team", "name", "size").
+			Where(isActive).
+			Where(hasEmail).
+			OrderBy("createdAt", xata.SortOrderDesc).
+			OrderByRandom().
+			Limit(50).
+			Offset(100).
+			Strong().
+			Build()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "users", request.TableName)
+		assert.Equal(t, "my-db", *request.DatabaseName)
+		assert.Equal(t, "dev", *request.BranchName)
+		assert.Equal(t, []string{"name", "owner.*", "team.name", "team.size"}, request.Payload.Columns)
+		assert.Equal(t, xata.ConsistencyStrong, request.Payload.Consistency)
+
+		payload, err := json.Marshal(map[string]any{
+			"filter": request.Payload.Filter,
+			"sort":   request.Payload.Sort,
+			"page":   request.Payload.Page,
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"filter": {"$all": [{"$exists": "active"}, {"$exists": "email"}]},
+			"sort": [{"createdAt": "desc"}, {"*": "random"}],
+			"page": {"size": 50, "offset": 100}
+		}`, string(payload))
+	})
+
+	t.Run("should build a single filter and a score sort", func(t *testing.T) {
+		request, err := xata.From("users").Where(isActive).OrderByScore().Build()
+		assert.NoError(t, err)
+		assert.Equal(t, isActive, request.Payload.Filter)
+		assert.Nil(t, request.Payload.Page)
+
+		sort, err := json.Marshal(request.Payload.Sort)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"xata.score": "desc"}]`, string(sort))
+	})
+
+	t.Run("should build cursor pagination", func(t *testing.T) {
+		request, err := xata.From("users").After("a").Before("b").Limit(10).Build()
+		assert.NoError(t, err)
+		assert.Equal(t, &xata.PageConfig{After: xata.String("a"), Before: xata.String("b"), Size: xata.Int(10)}, request.Payload.Page)
+	})
+
+	invalid := map[string]*xata.QueryBuilder{
+		"empty table":             xata.From(""),
+		"empty column":            xata.From("users").Select(""),
+		"empty link":              xata.From("users").Expand(""),
+		"nil filter":              xata.From("users").Where(nil),
+		"empty sort column":       xata.From("users").OrderBy("", xata.SortOrderAsc),
+		"random column sort":      xata.From("users").OrderBy("name", xata.SortOrderRandom),
+		"all columns sort":        xata.From("users").OrderBy("*", xata.SortOrderAsc),
+		"invalid sort order":      xata.From("users").OrderBy("name", xata.SortOrder(0)),
+		"random before a column":  xata.From("users").OrderByRandom().OrderBy("name", xata.SortOrderAsc),
+		"random and score":        xata.From("users").OrderByScore().OrderByRandom(),
+		"limit too small":         xata.From("users").Limit(0),
+		"limit too large":         xata.From("users").Limit(201),
+		"negative offset":         xata.From("users").Offset(-1),
+		"offset too large":        xata.From("users").Offset(801),
+		"start and end":           xata.From("users").Start("a").End("b"),
+		"start and after":         xata.From("users").Start("a").After("b"),
+		"cursor and filter":       xata.From("users").After("a").Where(isActive),
+		"cursor and sort":         xata.From("users").After("a").OrderBy("name", xata.SortOrderAsc),
+		"cursor and random order": xata.From("users").End("a").OrderByRandom(),
+	}
+
+	for name, builder := range invalid {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			_, err := builder.Build()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_searchAndFilterCli_Query_consistency(t *testing.T) {
+	var body map[string]any
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body = map[string]any{}
+		assert.NoError(t, json.Unmarshal(raw, &body))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"records": []}`))
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	request, err := xata.From("users").Database("my-db").Strong().Build()
+	assert.NoError(t, err)
+	_, err = cli.Query(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "strong", body["consistency"])
+
+	request, err = xata.From("users").Database("my-db").Build()
+	assert.NoError(t, err)
+	_, err = cli.Query(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.NotContains(t, body, "consistency")
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+// maxTransactionOperations is the maximum number of operations in a transaction.
+const maxTransactionOperations = 1000
+
+type DeleteWhereRequest struct {
+	RecordRequest
+	// Filter selects the records to delete. It is required, an empty filter matches all records.
+	Filter *FilterExpression
+	// BatchSize is the number of operations per transaction, at most 1000. 1000 by default.
+	BatchSize *int
+	// DryRun only counts the matching records.
+	DryRun bool
+	// OnProgress is called after each transaction.
+	OnProgress func(BulkWriteResult)
+}
+
+type UpdateWhereRequest struct {
+	RecordRequest
+	// Filter selects the records to update. It is required, an empty filter matches all records.
+	Filter *FilterExpression
+	// Changes are the values set on every matching record.
+	Changes map[string]*DataInputRecordValue
+	// BatchSize is the number of operations per transaction, at most 1000. 1000 by default.
+	BatchSize *int
+	// DryRun only counts the matching records.
+	DryRun bool
+	// OnProgress is called after each transaction.
+	OnProgress func(BulkWriteResult)
+}
+
+// BulkWriteResult reports the progress and result of DeleteWhere and UpdateWhere.
+type BulkWriteResult struct {
+	// Matched is the number of records matching the filter when the operation started.
+	Matched int
+	// Written is the number of records deleted or updated so far.
+	Written int
+	DryRun  bool
+}
+
+// DeleteWhere deletes all the records matching a filter, in batched transactions. The matching
+// records are read with the search client and deleted with the records client.
+// The operation isn't atomic: if a transaction fails, the records of the previous ones stay
+// deleted, and the result reports how many were.
+func DeleteWhere(ctx context.Context, records RecordsClient, search SearchAndFilterClient, request DeleteWhereRequest) (*BulkWriteResult, error) {
+	return writeWhere(ctx, records, search, bulkWrite{
+		RecordRequest: request.RecordRequest,
+		filter:        request.Filter,
+		batchSize:     request.BatchSize,
+		dryRun:        request.DryRun,
+		onProgress:    request.OnProgress,
+		operation: func(id string) TransactionOperation {
+			return NewDeleteTransaction(TransactionDeleteOp{Table: request.TableName, Id: id})
+		},
+	})
+}
+
+// UpdateWhere applies the same changes to all the records matching a filter, in batched
+// transactions. The matching records are read with the search client and updated with the
+// records client. The operation isn't atomic: if a transaction fails, the records of the
+// previous ones stay updated, and the result reports how many were.
+func UpdateWhere(ctx context.Context, records RecordsClient, search SearchAndFilterClient, request UpdateWhereRequest) (*BulkWriteResult, error) {
+	if len(request.Changes) == 0 {
+		return nil, fmt.Errorf("changes cannot be empty")
+	}
+
+	fields := make(map[string]any, len(request.Changes))
+	for k, v := range request.Changes {
+		fields[k] = (*xatagenworkspace.DataInputRecordValue)(v)
+	}
+
+	return writeWhere(ctx, records, search, bulkWrite{
+		RecordRequest: request.RecordRequest,
+		filter:        request.Filter,
+		batchSize:     request.BatchSize,
+		dryRun:        request.DryRun,
+		onProgress:    request.OnProgress,
+		operation: func(id string) TransactionOperation {
+			return NewUpdateTransaction(TransactionUpdateOp{Table: request.TableName, Id: id, Fields: fields})
+		},
+	})
+}
+
+// bulkWrite holds the parameters shared by DeleteWhere and UpdateWhere.
+type bulkWrite struct {
+	RecordRequest
+	filter     *FilterExpression
+	batchSize  *int
+	dryRun     bool
+	onProgress func(BulkWriteResult)
+	operation  func(id string) TransactionOperation
+}
+
+func writeWhere(ctx context.Context, records RecordsClient, search SearchAndFilterClient, w bulkWrite) (*BulkWriteResult, error) {
+	if w.filter == nil {
+		return nil, fmt.Errorf("filter cannot be nil, use an empty filter to match all records")
+	}
+
+	batchSize := maxTransactionOperations
+	if w.batchSize != nil {
+		if *w.batchSize < 1 || *w.batchSize > maxTransactionOperations {
+			return nil, fmt.Errorf("batch size must be between 1 and %d", maxTransactionOperations)
+		}
+		batchSize = *w.batchSize
+	}
+
+	// The IDs are all read before writing, so the count and the writes come from the same query,
+	// and the writes don't move the pages being read.
+	ids, err := matchingIDs(ctx, search, w)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the matching records: %w", err)
+	}
+
+	result := &BulkWriteResult{Matched: len(ids), DryRun: w.dryRun}
+	if w.dryRun {
+		return result, nil
+	}
+
+	for len(ids) > 0 {
+		n := min(batchSize, len(ids))
+		operations := make([]TransactionOperation, 0, n)
+		for _, id := range ids[:n] {
+			operations = append(operations, w.operation(id))
+		}
+
+		if _, err := records.Transaction(ctx, TransactionRequest{
+			RecordRequest: w.RecordRequest,
+			Operations:    operations,
+		}); err != nil {
+			return result, err
+		}
+
+		ids = ids[n:]
+		result.Written += n
+		if w.onProgress != nil {
+			w.onProgress(*result)
+		}
+	}
+
+	return result, nil
+}
+
+// matchingIDs pages through the records matching the filter and returns their IDs.
+func matchingIDs(ctx context.Context, search SearchAndFilterClient, w bulkWrite) ([]string, error) {
+	// Only the first page carries the filter, the next ones are read from the cursor.
+	query := QueryTableRequest{
+		BranchRequestOptional: BranchRequestOptional{DatabaseName: w.DatabaseName, BranchName: w.BranchName},
+		TableName:             w.TableName,
+		Payload: QueryTableRequestPayload{
+			Filter:  w.filter,
+			Columns: []string{"id"},
+			Page:    &PageConfig{Size: Int(maxQueryPageSize)},
+		},
+	}
+
+	var ids []string
+	for {
+		page, err := search.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range page.Records {
+			id, ok := (*rec)["id"].(string)
+			if !ok {
+				return nil, fmt.Errorf("record without id in the query response")
+			}
+			ids = append(ids, id)
+		}
+
+		if page.Meta == nil || page.Meta.Page == nil || !page.Meta.Page.More {
+			return ids, nil
+		}
+
+		query.Payload.Filter = nil
+		query.Payload.Page = &PageConfig{Size: Int(maxQueryPageSize), After: String(page.Meta.Page.Cursor)}
+	}
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+)
+
+// bulkWriteService serves the query and transaction endpoints for a table of records numbered
+// from 0.
+type bulkWriteService struct {
+	t       *testing.T
+	records int
+	failAt  int
+
+	mu           sync.Mutex
+	queries      []map[string]any
+	transactions [][]map[string]any
+}
+
+func (b *bulkWriteService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var body map[string]any
+	assert.NoError(b.t, json.NewDecoder(r.Body).Decode(&body))
+
+	var resp any
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/query"):
+		b.queries = append(b.queries, body)
+
+		start := 0
+		if page, ok := body["page"].(map[string]any); ok {
+			if after, ok := page["after"].(string); ok {
+				start, _ = strconv.Atoi(after)
+			}
+		}
+		end := start + 200
+		if end > b.records {
+			end = b.records
+		}
+
+		records := []map[string]any{}
+		for i := start; i < end; i++ {
+			records = append(records, map[string]any{"id": fmt.Sprintf("rec_%d", i)})
+		}
+		resp = map[string]any{
+			"records": records,
+			"meta":    map[string]any{"page": map[string]any{"cursor": strconv.Itoa(end), "more": end < b.records, "size": 200}},
+		}
+	case strings.HasSuffix(r.URL.Path, "/transaction"):
+		var ops []map[string]any
+		for _, op := range body["operations"].([]any) {
+			ops = append(ops, op.(map[string]any))
+		}
+		b.transactions = append(b.transactions, ops)
+		if len(b.transactions) == b.failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "transaction failed"})
+			return
+		}
+		resp = map[string]any{"results": []any{}}
+	default:
+		// Not t.Fatal, the handler doesn't run in the test goroutine.
+		b.t.Errorf("unexpected path: %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	assert.NoError(b.t, json.NewEncoder(w).Encode(resp))
+}
+
+func TestDeleteWhere(t *testing.T) {
+	filter := &xata.FilterExpression{Exists: xata.String("archivedAt")}
+
+	deleteWhere := func(t *testing.T, svc *bulkWriteService, request xata.DeleteWhereRequest) (*xata.BulkWriteResult, error) {
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		records, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+		search, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+		return xata.DeleteWhere(context.TODO(), records, search, request)
+	}
+
+	t.Run("should delete the matching records in batches", func(t *testing.T) {
+		svc := &bulkWriteService{t: t, records: 450}
+
+		var progress []xata.BulkWriteResult
+		got, err := deleteWhere(t, svc, xata.DeleteWhereRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"},
+			Filter:        filter,
+			BatchSize:     xata.Int(100),
+			OnProgress:    func(p xata.BulkWriteResult) { progress = append(progress, p) },
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &xata.BulkWriteResult{Matched: 450, Written: 450}, got)
+
+		assert.Len(t, svc.queries, 3)
+		assert.Equal(t, map[string]any{"$exists": "archivedAt"}, svc.queries[0]["filter"])
+		assert.Equal(t, []any{"id"}, svc.queries[0]["columns"])
+		assert.NotContains(t, svc.queries[1], "filter")
+
+		var sizes []int
+		for _, tx := range svc.transactions {
+			sizes = append(sizes, len(tx))
+		}
+		assert.Equal(t, []int{100, 100, 100, 100, 50}, sizes)
+		assert.Equal(t, map[string]any{"delete": map[string]any{"table": "users", "id": "rec_0"}}, svc.transactions[0][0])
+		assert.Equal(t, map[string]any{"delete": map[string]any{"table": "users", "id": "rec_449"}}, svc.transactions[4][49])
+
+		assert.Len(t, progress, 5)
+		assert.Equal(t, xata.BulkWriteResult{Matched: 450, Written: 200}, progress[1])
+	})
+
+	t.Run("should only count with the query on dry run", func(t *testing.T) {
+		svc := &bulkWriteService{t: t, records: 42}
+		got, err := deleteWhere(t, svc, xata.DeleteWhereRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"},
+			Filter:        filter,
+			DryRun:        true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &xata.BulkWriteResult{Matched: 42, DryRun: true}, got)
+		assert.Len(t, svc.queries, 1)
+		assert.Empty(t, svc.transactions)
+	})
+
+	t.Run("should report the written records on failure", func(t *testing.T) {
+		svc := &bulkWriteService{t: t, records: 300, failAt: 2}
+		got, err := deleteWhere(t, svc, xata.DeleteWhereRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"},
+			Filter:        filter,
+			BatchSize:     xata.Int(100),
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 100, got.Written)
+	})
+
+	invalid := map[string]xata.DeleteWhereRequest{
+		"nil filter":      {RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"}},
+		"zero batch size": {RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"}, Filter: filter, BatchSize: xata.Int(0)},
+		"big batch size":  {RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"}, Filter: filter, BatchSize: xata.Int(1001)},
+	}
+	for name, request := range invalid {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			svc := &bulkWriteService{t: t, records: 1}
+			_, err := deleteWhere(t, svc, request)
+			assert.Error(t, err)
+			assert.Empty(t, svc.transactions)
+		})
+	}
+}
+
+func TestUpdateWhere(t *testing.T) {
+	svc := &bulkWriteService{t: t, records: 3}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	records, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+	search, err := xata.NewSearchAndFilterClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	request := xata.UpdateWhereRequest{
+		RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "users"},
+		Filter:        &xata.FilterExpression{ExistsNot: xata.String("plan")},
+		Changes:       map[string]*xata.DataInputRecordValue{"plan": xata.ValueFromString("free")},
+	}
+
+	got, err := xata.UpdateWhere(context.TODO(), records, search, request)
+	assert.NoError(t, err)
+	assert.Equal(t, &xata.BulkWriteResult{Matched: 3, Written: 3}, got)
+	assert.Len(t, svc.transactions, 1)
+	assert.Equal(t, map[string]any{"update": map[string]any{
+		"table":  "users",
+		"id":     "rec_2",
+		"fields": map[string]any{"plan": "free"},
+	}}, svc.transactions[0][2])
+
+	request.Changes = nil
+	_, err = xata.UpdateWhere(context.TODO(), records, search, request)
+	assert.Error(t, err)
+}
@@ -5,7 +5,9 @@ package xata
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -38,16 +40,20 @@ type BulkInsertRecordRequest struct {
 
 type InsertRecordWithIDRequest struct {
 	RecordRequest
-	RecordID   string
+	RecordID string
+	// CreateOnly fails the request if a record with the same ID already exists,
+	// instead of replacing it.
 	CreateOnly *bool
-	IfVersion  *int
-	Columns    []string
-	Body       map[string]*DataInputRecordValue
+	// IfVersion only replaces the record if its current version matches.
+	IfVersion *int
+	Columns   []string
+	Body      map[string]*DataInputRecordValue
 }
 
 type UpdateRecordRequest struct {
 	RecordRequest
-	RecordID  string
+	RecordID string
+	// IfVersion only updates the record if its current version matches.
 	IfVersion *int
 	Columns   []string
 	Body      map[string]*DataInputRecordValue
@@ -55,6 +61,13 @@ type UpdateRecordRequest struct {
 
 type UpsertRecordRequest UpdateRecordRequest
 
+// UpsertRecordResponse is the record returned by UpsertWithStatus.
+type UpsertRecordResponse struct {
+	Record
+	// Created is true if the record was inserted, false if an existing record was updated.
+	Created bool
+}
+
 type GetRecordRequest struct {
 	RecordRequest
 	RecordID string
@@ -64,6 +77,8 @@ type GetRecordRequest struct {
 type DeleteRecordRequest struct {
 	RecordRequest
 	RecordID string
+	// Columns are the columns of the deleted record to return.
+	Columns []string
 }
 
 type RecordMeta struct {
@@ -222,6 +237,10 @@ func (r recordsClient) BulkInsert(ctx context.Context, request BulkInsertRecordR
 // InsertWithID inserts a record with ID.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/data/record_id#insert-record-with-id
 func (r recordsClient) InsertWithID(ctx context.Context, request InsertRecordWithIDRequest) (*Record, error) {
+	if request.CreateOnly != nil && *request.CreateOnly && request.IfVersion != nil {
+		return nil, fmt.Errorf("create only and if version cannot be both set")
+	}
+
 	recGen := &xatagenworkspace.InsertRecordWithIdRequest{
 		CreateOnly: request.CreateOnly,
 		IfVersion:  request.IfVersion,
@@ -240,7 +259,7 @@ func (r recordsClient) InsertWithID(ctx context.Context, request InsertRecordWit
 
 	record, err := r.generated.InsertRecordWithId(ctx, dbBranchName, request.TableName, request.RecordID, recGen)
 	if err != nil {
-		return nil, err
+		return nil, versionConflict(request.IfVersion, err)
 	}
 
 	respRec, err := constructRecord(*record)
@@ -271,7 +290,7 @@ func (r recordsClient) Update(ctx context.Context, request UpdateRecordRequest)
 
 	record, err := r.generated.UpdateRecordWithId(ctx, dbBranchName, request.TableName, request.RecordID, recGen)
 	if err != nil {
-		return nil, err
+		return nil, versionConflict(request.IfVersion, err)
 	}
 
 	respRec, err := constructRecord(*record)
@@ -285,7 +304,7 @@ func (r recordsClient) Update(ctx context.Context, request UpdateRecordRequest)
 // Upsert inserts or updates a record.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/data/record_id#upsert-record-with-id
 func (r recordsClient) Upsert(ctx context.Context, request UpsertRecordRequest) (*Record, error) {
-	recGen := &xatagenworkspace.UpdateRecordWithIdRequest{
+	recGen := &xatagenworkspace.UpsertRecordWithIdRequest{
 		IfVersion: request.IfVersion,
 		Columns:   constructColumns(request.Columns),
 		Body:      make(map[string]*xatagenworkspace.DataInputRecordValue),
@@ -300,9 +319,9 @@ func (r recordsClient) Upsert(ctx context.Context, request UpsertRecordRequest)
 		return nil, err
 	}
 
-	record, err := r.generated.UpdateRecordWithId(ctx, dbBranchName, request.TableName, request.RecordID, recGen)
+	record, err := r.generated.UpsertRecordWithId(ctx, dbBranchName, request.TableName, request.RecordID, recGen)
 	if err != nil {
-		return nil, err
+		return nil, versionConflict(request.IfVersion, err)
 	}
 
 	respRec, err := constructRecord(*record)
@@ -313,6 +332,21 @@ func (r recordsClient) Upsert(ctx context.Context, request UpsertRecordRequest)
 	return respRec, nil
 }
 
+// UpsertWithStatus upserts a record like client.Upsert and reports whether it was created.
+// Created is only set by the clients of NewRecordsClient, it's always false for other clients.
+func UpsertWithStatus(ctx context.Context, client RecordsClient, request UpsertRecordRequest) (*UpsertRecordResponse, error) {
+	rec := &recordedResponse{}
+	record, err := client.Upsert(withRecordedResponse(ctx, rec), request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpsertRecordResponse{
+		Record:  *record,
+		Created: rec.statusCode == http.StatusCreated,
+	}, nil
+}
+
 // Get gets a record by its ID.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/data/record_id#get-record-by-id
 func (r recordsClient) Get(ctx context.Context, request GetRecordRequest) (*Record, error) {
@@ -404,7 +438,57 @@ func (r recordsClient) Delete(ctx context.Context, request DeleteRecordRequest)
 		return err
 	}
 
-	return r.generated.DeleteRecord(ctx, dbBranchName, request.TableName, request.RecordID)
+	_, err = r.generated.DeleteRecord(ctx, dbBranchName, request.TableName, request.RecordID, &xatagenworkspace.DeleteRecordRequest{
+		Columns: constructColumns(request.Columns),
+	})
+	return err
+}
+
+// DeleteReturning deletes a record like client.Delete and returns it as it was before the
+// deletion, or nil if the API returned no content. The record is only returned by the clients of
+// NewRecordsClient, it's always nil for other clients.
+func DeleteReturning(ctx context.Context, client RecordsClient, request DeleteRecordRequest) (*Record, error) {
+	rec := &recordedResponse{}
+	if err := client.Delete(withRecordedResponse(ctx, rec), request); err != nil {
+		return nil, err
+	}
+	if len(rec.body) == 0 {
+		return nil, nil
+	}
+
+	var record xatagenworkspace.Record
+	if err := json.Unmarshal(rec.body, &record); err != nil {
+		return nil, err
+	}
+	return constructRecord(record)
+}
+
+// versionConflictError is the error of a conditional write rejected by the API, see
+// IsVersionConflict.
+type versionConflictError struct {
+	err error
+}
+
+func (v *versionConflictError) Error() string { return v.err.Error() }
+
+func (v *versionConflictError) Unwrap() error { return v.err }
+
+// versionConflict marks the unprocessable entity errors of the writes conditioned by ifVersion
+// as version conflicts.
+func versionConflict(ifVersion *int, err error) error {
+	var unprocessable *xatagenworkspace.UnprocessableEntityError
+	if ifVersion == nil || !errors.As(err, &unprocessable) {
+		return err
+	}
+	return &versionConflictError{err: err}
+}
+
+// IsVersionConflict reports whether err was returned by a conditional write whose IfVersion
+// doesn't match the current version of the record, i.e. an unprocessable entity error of an
+// Insert, Update or Upsert with IfVersion set.
+func IsVersionConflict(err error) bool {
+	var conflict *versionConflictError
+	return errors.As(err, &conflict)
 }
 
 func (r recordsClient) dbBranchName(request RecordRequest) (string, error) {
@@ -491,7 +575,7 @@ func NewRecordsClient(opts ...ClientOption) (RecordsClient, error) {
 	return recordsClient{
 			generated: xatagenworkspace.NewRecordsClient(
 				func(options *xatagenclient.ClientOptions) {
-					options.HTTPClient = cliOpts.HTTPClient
+					options.HTTPClient = responseRecorder{next: cliOpts.HTTPClient}
 					options.BaseURL = cliOpts.BaseURL
 					options.Bearer = cliOpts.Bearer
 				}),
@@ -4,6 +4,7 @@ package xata_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -310,20 +311,29 @@ func Test_recordsClient_Upsert(t *testing.T) {
 	assert := assert.New(t)
 
 	type tc struct {
-		name       string
-		want       *xata.Record
-		statusCode int
-		apiErr     *xatagencore.APIError
+		name        string
+		want        *xata.Record
+		wantCreated bool
+		statusCode  int
+		apiErr      *xatagencore.APIError
 	}
 
 	tests := []tc{
 		{
-			name: "should upsert a record successfully",
+			name: "should upsert an existing record successfully",
 			want: &xata.Record{
 				RecordMeta: xata.RecordMeta{Id: "some-id"},
 			},
 			statusCode: http.StatusOK,
 		},
+		{
+			name: "should upsert a new record successfully",
+			want: &xata.Record{
+				RecordMeta: xata.RecordMeta{Id: "some-id"},
+			},
+			wantCreated: true,
+			statusCode:  http.StatusCreated,
+		},
 	}
 
 	for _, eTC := range errTestCasesWorkspace {
@@ -336,13 +346,13 @@ func Test_recordsClient_Upsert(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			testSrv := testService(t, http.MethodPatch, "/db", tt.statusCode, tt.apiErr != nil, tt.want)
+			testSrv := testService(t, http.MethodPost, "/db", tt.statusCode, tt.apiErr != nil, tt.want)
 
 			cli, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
 			assert.NoError(err)
 			assert.NotNil(cli)
 
-			got, err := cli.Upsert(context.TODO(), xata.UpsertRecordRequest{
+			got, err := xata.UpsertWithStatus(context.TODO(), cli, xata.UpsertRecordRequest{
 				RecordRequest: xata.RecordRequest{
 					DatabaseName: xata.String("test-db"),
 					BranchName:   xata.String("main"),
@@ -364,6 +374,7 @@ func Test_recordsClient_Upsert(t *testing.T) {
 			} else {
 				assert.NoError(err)
 				assert.Equal(tt.want.Id, got.Id)
+				assert.Equal(tt.wantCreated, got.Created)
 			}
 		})
 	}
@@ -532,6 +543,7 @@ func Test_recordsClient_Delete(t *testing.T) {
 
 	type tc struct {
 		name       string
+		want       *xata.Record
 		statusCode int
 		apiErr     *xatagencore.APIError
 	}
@@ -541,6 +553,14 @@ func Test_recordsClient_Delete(t *testing.T) {
 			name:       "should delete a record",
 			statusCode: http.StatusNoContent,
 		},
+		{
+			name: "should delete a record and return it",
+			want: &xata.Record{
+				RecordMeta: xata.RecordMeta{Id: "some-id"},
+				Data:       map[string]interface{}{"test-column": "test-value"},
+			},
+			statusCode: http.StatusOK,
+		},
 	}
 
 	for _, eTC := range errTestCasesWorkspace {
@@ -559,19 +579,20 @@ func Test_recordsClient_Delete(t *testing.T) {
 				"/db",
 				tt.statusCode,
 				tt.apiErr != nil,
-				nil,
+				deleteResponse(tt.want),
 			)
 
 			cli, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
 			assert.NoError(err)
 			assert.NotNil(cli)
 
-			err = cli.Delete(context.TODO(), xata.DeleteRecordRequest{
+			got, err := xata.DeleteReturning(context.TODO(), cli, xata.DeleteRecordRequest{
 				RecordRequest: xata.RecordRequest{
 					DatabaseName: xata.String("test-db"),
 					BranchName:   xata.String("main"),
 					TableName:    "test-table",
 				},
+				Columns: []string{"test-column"},
 			})
 
 			if tt.apiErr != nil {
@@ -581,9 +602,71 @@ func Test_recordsClient_Delete(t *testing.T) {
 				}
 				assert.ErrorAs(err, &errAPI)
 				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
 			} else {
 				assert.NoError(err)
+				assert.Equal(tt.want, got)
 			}
 		})
 	}
 }
+
+func deleteResponse(record *xata.Record) any {
+	if record == nil {
+		return nil
+	}
+
+	resp := map[string]interface{}{"id": record.Id}
+	for k, v := range record.Data {
+		resp[k] = v
+	}
+	return resp
+}
+
+func Test_recordsClient_conditionalWrites(t *testing.T) {
+	t.Run("should reject create only with if version", func(t *testing.T) {
+		cli, err := xata.NewRecordsClient(xata.WithBaseURL("http://localhost"), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		_, err = cli.InsertWithID(context.TODO(), xata.InsertRecordWithIDRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "test-table"},
+			RecordID:      "test-id",
+			CreateOnly:    xata.Bool(true),
+			IfVersion:     xata.Int(1),
+		})
+		assert.Error(t, err)
+	})
+
+	update := func(t *testing.T, statusCode int, ifVersion *int) error {
+		testSrv := testService(t, http.MethodPatch, "/db", statusCode, false, errBody{ID: "test-err-id", Message: "test-message"})
+		defer testSrv.Close()
+
+		cli, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		_, err = cli.Update(context.TODO(), xata.UpdateRecordRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "test-table"},
+			RecordID:      "test-id",
+			IfVersion:     ifVersion,
+		})
+		return err
+	}
+
+	t.Run("should report version conflicts", func(t *testing.T) {
+		err := update(t, http.StatusUnprocessableEntity, xata.Int(1))
+		assert.True(t, xata.IsVersionConflict(err))
+		assert.False(t, xata.IsVersionConflict(fmt.Errorf("another error")))
+	})
+
+	t.Run("should not report other errors as version conflicts", func(t *testing.T) {
+		err := update(t, http.StatusBadRequest, xata.Int(1))
+		assert.Error(t, err)
+		assert.False(t, xata.IsVersionConflict(err))
+	})
+
+	t.Run("should not report unconditional writes as version conflicts", func(t *testing.T) {
+		err := update(t, http.StatusUnprocessableEntity, nil)
+		assert.Error(t, err)
+		assert.False(t, xata.IsVersionConflict(err))
+	})
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultModifyMaxAttempts = 5
+	defaultModifyBackoff     = 50 * time.Millisecond
+)
+
+type ModifyRecordRequest struct {
+	RecordRequest
+	RecordID string
+	// Columns are the columns read, passed to the mutation and returned. All columns by default.
+	Columns []string
+	// MaxAttempts is the number of times the record is read and written before giving up on
+	// conflicts. 5 by default.
+	MaxAttempts *int
+	// Backoff is the delay before the first retry, doubled after each conflict. 50ms by default.
+	Backoff *time.Duration
+}
+
+// ModifyFunc returns the changes to apply to the record. It may be called several times, once
+// per attempt, with a freshly read record each time, so it must not have side effects. Returning
+// no changes leaves the record untouched.
+type ModifyFunc func(record *Record) (map[string]*DataInputRecordValue, error)
+
+// Modify reads a record, applies the mutation and writes the changes only if the record wasn't
+// modified in between, using its version. On a version conflict, the record is read again and
+// the mutation applied again, up to MaxAttempts times. The last conflict error is returned when
+// all attempts fail, IsVersionConflict reports true for it. Other errors are returned right away.
+func Modify(ctx context.Context, client RecordsClient, request ModifyRecordRequest, mutate ModifyFunc) (*Record, error) {
+	maxAttempts := defaultModifyMaxAttempts
+	if request.MaxAttempts != nil {
+		if *request.MaxAttempts < 1 {
+			return nil, fmt.Errorf("max attempts must be at least 1")
+		}
+		maxAttempts = *request.MaxAttempts
+	}
+
+	backoff := defaultModifyBackoff
+	if request.Backoff != nil {
+		backoff = *request.Backoff
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			if err := sleep(ctx, backoff); err != nil {
+				return nil, err
+			}
+			backoff *= 2
+		}
+
+		var record *Record
+		record, err = modify(ctx, client, request, mutate)
+		if err == nil {
+			return record, nil
+		}
+		if !IsVersionConflict(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("record %s was modified concurrently %d times: %w", request.RecordID, maxAttempts, err)
+}
+
+func modify(ctx context.Context, client RecordsClient, request ModifyRecordRequest, mutate ModifyFunc) (*Record, error) {
+	record, err := client.Get(ctx, GetRecordRequest{
+		RecordRequest: request.RecordRequest,
+		RecordID:      request.RecordID,
+		Columns:       request.Columns,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record.Xata == nil {
+		return nil, fmt.Errorf("record %s has no version", request.RecordID)
+	}
+
+	changes, err := mutate(record)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return record, nil
+	}
+
+	return client.Update(ctx, UpdateRecordRequest{
+		RecordRequest: request.RecordRequest,
+		RecordID:      request.RecordID,
+		IfVersion:     Int(record.Xata.Version),
+		Columns:       request.Columns,
+		Body:          changes,
+	})
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
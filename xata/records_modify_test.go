@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+)
+
+// counterService serves a single record holding a counter. The first `conflicts` writes are
+// rejected as if another client had modified the record in between.
+type counterService struct {
+	mu        sync.Mutex
+	count     float64
+	version   int
+	conflicts int
+	// invalid rejects the writes with an unprocessable entity error that isn't a conflict.
+	invalid bool
+	reads   int
+	writes  int
+}
+
+func (c *counterService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		c.reads++
+	case http.MethodPatch:
+		c.writes++
+		if c.invalid {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid value for column [count]"})
+			return
+		}
+		ifVersion, err := strconv.Atoi(r.URL.Query().Get("ifVersion"))
+		if err != nil || ifVersion != c.version || c.conflicts > 0 {
+			c.conflicts--
+			c.version++
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "version mismatch"})
+			return
+		}
+
+		var body map[string]float64
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.count = body["count"]
+		c.version++
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":    "counter",
+		"count": c.count,
+		"xata":  map[string]any{"version": c.version},
+	})
+}
+
+func increment(record *xata.Record) (map[string]*xata.DataInputRecordValue, error) {
+	return map[string]*xata.DataInputRecordValue{
+		"count": xata.ValueFromDouble(record.Data["count"].(float64) + 1),
+	}, nil
+}
+
+func TestModify(t *testing.T) {
+	request := xata.ModifyRecordRequest{
+		RecordRequest: xata.RecordRequest{DatabaseName: xata.String("test-db"), TableName: "counters"},
+		RecordID:      "counter",
+		Backoff:       func() *time.Duration { d := time.Millisecond; return &d }(),
+	}
+
+	newClient := func(t *testing.T, svc *counterService) xata.RecordsClient {
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		cli, err := xata.NewRecordsClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+		return cli
+	}
+
+	t.Run("should modify the record", func(t *testing.T) {
+		svc := &counterService{}
+		got, err := xata.Modify(context.TODO(), newClient(t, svc), request, increment)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, got.Data["count"])
+		assert.Equal(t, 1, got.Xata.Version)
+		assert.Equal(t, 1, svc.writes)
+	})
+
+	t.Run("should retry with a fresh read on conflicts", func(t *testing.T) {
+		svc := &counterService{count: 10, conflicts: 2}
+		got, err := xata.Modify(context.TODO(), newClient(t, svc), request, increment)
+		assert.NoError(t, err)
+		assert.Equal(t, 11.0, got.Data["count"])
+		assert.Equal(t, 3, svc.reads)
+		assert.Equal(t, 3, svc.writes)
+	})
+
+	t.Run("should give up after max attempts", func(t *testing.T) {
+		svc := &counterService{conflicts: 10}
+		req := request
+		req.MaxAttempts = xata.Int(3)
+
+		got, err := xata.Modify(context.TODO(), newClient(t, svc), req, increment)
+		assert.Error(t, err)
+		assert.True(t, xata.IsVersionConflict(err))
+		assert.Nil(t, got)
+		assert.Equal(t, 3, svc.writes)
+		assert.Equal(t, 0.0, svc.count)
+	})
+
+	t.Run("should not retry other errors", func(t *testing.T) {
+		svc := &counterService{invalid: true}
+		got, err := xata.Modify(context.TODO(), newClient(t, svc), request, increment)
+		assert.Error(t, err)
+		assert.False(t, xata.IsVersionConflict(err))
+		assert.Nil(t, got)
+		assert.Equal(t, 1, svc.reads)
+		assert.Equal(t, 1, svc.writes)
+	})
+
+	t.Run("should not write without changes", func(t *testing.T) {
+		svc := &counterService{count: 3}
+		got, err := xata.Modify(context.TODO(), newClient(t, svc), request, func(*xata.Record) (map[string]*xata.DataInputRecordValue, error) {
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, got.Data["count"])
+		assert.Equal(t, 0, svc.writes)
+	})
+
+	t.Run("should return the mutation error", func(t *testing.T) {
+		svc := &counterService{}
+		_, err := xata.Modify(context.TODO(), newClient(t, svc), request, func(*xata.Record) (map[string]*xata.DataInputRecordValue, error) {
+			return nil, errors.New("invalid transition")
+		})
+		assert.EqualError(t, err, "invalid transition")
+		assert.Equal(t, 0, svc.writes)
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		svc := &counterService{conflicts: 10}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := xata.Modify(ctx, newClient(t, svc), request, increment)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should reject invalid max attempts", func(t *testing.T) {
+		req := request
+		req.MaxAttempts = xata.Int(0)
+		_, err := xata.Modify(context.TODO(), newClient(t, &counterService{}), req, increment)
+		assert.Error(t, err)
+	})
+}
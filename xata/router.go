@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRouteTTL = 5 * time.Minute
+	// routerBaseURL is the base URL of the clients using a router. The router replaces the
+	// host of every request, so it's never resolved.
+	routerBaseURL = "https://router.invalid"
+)
+
+// Router sends the requests of the workspace API clients to the region of the database they
+// target, so that one client can work with all the databases of a workspace.
+// The regions are looked up with DatabasesClient.List and cached.
+type Router struct {
+	databases   DatabasesClient
+	next        httpClient
+	workspaceID string
+	dbCfg       databaseConfig
+	ttl         time.Duration
+
+	mu        sync.Mutex
+	regions   map[string]string
+	fetchedAt time.Time
+	// lookup is the database list in flight, shared by the concurrent refreshes.
+	lookup *regionsLookup
+}
+
+// regionsLookup is a database list, done once closed.
+type regionsLookup struct {
+	done    chan struct{}
+	regions map[string]string
+	err     error
+}
+
+// NewRouter constructs a router for the workspace of the options. The regions are cached for
+// ttl, 5 minutes if zero, and looked up again when a database isn't known.
+func NewRouter(ttl time.Duration, opts ...ClientOption) (*Router, error) {
+	cliOpts, err := consolidateClientOptionsForCore(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCfg, err := loadDatabaseConfig(cliOpts)
+	if err != nil {
+		return nil, err
+	}
+	if dbCfg.workspaceID == "" && dbCfg.customURL == "" {
+		return nil, fmt.Errorf("workspace ID cannot be empty")
+	}
+
+	databases, err := NewDatabasesClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl <= 0 {
+		ttl = defaultRouteTTL
+	}
+
+	// The clients using the router wrap it with their credentials and timeouts, so it sends the
+	// requests with the HTTP client of the options as is, for them not to apply twice.
+	raw := &ClientOptions{}
+	for _, opt := range opts {
+		opt(raw)
+	}
+	next := raw.HTTPClient
+	if next == nil {
+		next = http.DefaultClient
+	}
+
+	return &Router{
+		databases:   databases,
+		next:        next,
+		workspaceID: dbCfg.workspaceID,
+		dbCfg:       dbCfg,
+		ttl:         ttl,
+	}, nil
+}
+
+// WithRouter sends the requests of a workspace API client through a router, so that
+// DatabaseName picks the database and its region on every request.
+// The router sends the requests with the HTTP client given to NewRouter, set it there: the
+// HTTP client and the base URL of the client options are ignored, whatever their order.
+// The credentials and the timeouts are the ones of the client options.
+func WithRouter(router *Router) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		options.Router = router
+	}
+}
+
+// Region returns the region of a database.
+func (r *Router) Region(ctx context.Context, dbName string) (string, error) {
+	regions, fresh := r.cached()
+
+	refreshed := false
+	if !fresh {
+		var err error
+		if regions, err = r.refresh(ctx); err != nil {
+			return "", err
+		}
+		refreshed = true
+	}
+
+	region, ok := regions[dbName]
+	if !ok && !refreshed {
+		// The database may have been created since the last lookup.
+		var err error
+		if regions, err = r.refresh(ctx); err != nil {
+			return "", err
+		}
+		region, ok = regions[dbName]
+	}
+	if !ok {
+		return "", fmt.Errorf("database %s not found in workspace %s", dbName, r.workspaceID)
+	}
+
+	return region, nil
+}
+
+// Invalidate drops the cached regions, e.g. after a database was moved.
+func (r *Router) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.regions = nil
+}
+
+// cached returns the cached regions, and whether they're still fresh.
+func (r *Router) cached() (map[string]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.regions, r.regions != nil && time.Since(r.fetchedAt) <= r.ttl
+}
+
+// refresh lists the databases again, without holding the lock, so that the requests to the
+// known databases aren't held up. The concurrent refreshes share a single list.
+func (r *Router) refresh(ctx context.Context) (map[string]string, error) {
+	r.mu.Lock()
+	lookup := r.lookup
+	if lookup == nil {
+		lookup = &regionsLookup{done: make(chan struct{})}
+		r.lookup = lookup
+
+		// Not cancelled with the context of the first caller, the others wait for it too.
+		go r.list(context.WithoutCancel(ctx), lookup)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-lookup.done:
+		return lookup.regions, lookup.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *Router) list(ctx context.Context, lookup *regionsLookup) {
+	defer close(lookup.done)
+
+	list, err := r.databases.ListWithWorkspaceID(ctx, r.workspaceID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookup = nil
+	if err != nil {
+		lookup.err = fmt.Errorf("unable to list the databases: %w", err)
+		return
+	}
+
+	lookup.regions = make(map[string]string, len(list.Databases))
+	for _, db := range list.Databases {
+		lookup.regions[db.Name] = db.Region
+	}
+	r.regions = lookup.regions
+	r.fetchedAt = time.Now()
+}
+
+// Do sends the request to the data plane host of the database in its path.
+func (r *Router) Do(req *http.Request) (*http.Response, error) {
+	dbName, err := routedDatabase(req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A custom data plane, e.g. a local emulator, serves every region.
+	var region string
+	if r.dbCfg.customURL == "" {
+		region, err = r.Region(req.Context(), dbName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := url.Parse(r.dbCfg.dataPlaneURL(region))
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	req.URL.Path = strings.TrimSuffix(target.Path, "/") + req.URL.Path
+	req.URL.RawPath = ""
+	req.Host = ""
+
+	return r.next.Do(req)
+}
+
+// routedDatabase extracts the database name of a workspace API path, either
+// /db/{db_name}:{branch_name}/... or /dbs/{db_name}/...
+func routedDatabase(path string) (string, error) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) >= 2 && segments[1] != "" {
+		switch segments[0] {
+		case "db":
+			dbName, _, _ := strings.Cut(segments[1], ":")
+			return dbName, nil
+		case "dbs":
+			return segments[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to route %s: no database in the path", path)
+}
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// regionsService serves the database list of the control plane, and the records of the data
+// plane, answering with the host the request was meant for.
+type regionsService struct {
+	t       *testing.T
+	regions map[string]string
+
+	mu    sync.Mutex
+	lists int
+}
+
+func (s *regionsService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/workspaces/ws-id/dbs" {
+		s.lists++
+		var databases []map[string]string
+		for name, region := range s.regions {
+			databases = append(databases, map[string]string{"name": name, "region": region})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"databases": databases})
+		return
+	}
+
+	assert.True(s.t, strings.HasPrefix(r.URL.Path, "/db/"), r.URL.Path)
+	_ = json.NewEncoder(w).Encode(map[string]any{"id": r.Header.Get("X-Routed-Host")})
+}
+
+// hostRecorder sends every request to the test server, keeping the original host in a header.
+type hostRecorder struct {
+	target *url.URL
+}
+
+func (h hostRecorder) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Routed-Host", req.URL.Host)
+	req.URL.Scheme = h.target.Scheme
+	req.URL.Host = h.target.Host
+	return http.DefaultClient.Do(req)
+}
+
+func TestRouter(t *testing.T) {
+	svc := &regionsService{t: t, regions: map[string]string{"users": "us-east-1", "orders": "eu-central-1"}}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	target, err := url.Parse(testSrv.URL)
+	assert.NoError(t, err)
+
+	router, err := xata.NewRouter(time.Hour,
+		xata.WithBaseURL(testSrv.URL),
+		xata.WithAPIKey("test-key"),
+		xata.WithWorkspaceID("ws-id"),
+		xata.WithHTTPClient(hostRecorder{target: target}),
+	)
+	assert.NoError(t, err)
+
+	cli, err := xata.NewRecordsClient(xata.WithRouter(router), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	get := func(dbName string) (*xata.Record, error) {
+		return cli.Get(context.TODO(), xata.GetRecordRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String(dbName), BranchName: xata.String("main"), TableName: "t"},
+			RecordID:      "rec",
+		})
+	}
+
+	t.Run("should route each database to its region", func(t *testing.T) {
+		got, err := get("users")
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-id.us-east-1.xata.sh", got.Id)
+
+		got, err = get("orders")
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-id.eu-central-1.xata.sh", got.Id)
+		assert.Equal(t, 1, svc.lists)
+	})
+
+	t.Run("should look up unknown databases again", func(t *testing.T) {
+		svc.mu.Lock()
+		svc.regions["events"] = "ap-southeast-2"
+		svc.mu.Unlock()
+
+		got, err := get("events")
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-id.ap-southeast-2.xata.sh", got.Id)
+		assert.Equal(t, 2, svc.lists)
+
+		_, err = get("missing")
+		assert.ErrorContains(t, err, "database missing not found in workspace ws-id")
+		assert.Equal(t, 3, svc.lists)
+	})
+
+	t.Run("should route whatever the order of the options", func(t *testing.T) {
+		cli, err := xata.NewRecordsClient(xata.WithRouter(router), xata.WithHTTPClient(http.DefaultClient), xata.WithBaseURL("https://example.com"), xata.WithAPIKey("test-key"))
+		assert.NoError(t, err)
+
+		got, err := cli.Get(context.TODO(), xata.GetRecordRequest{
+			RecordRequest: xata.RecordRequest{DatabaseName: xata.String("orders"), BranchName: xata.String("main"), TableName: "t"},
+			RecordID:      "rec",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-id.eu-central-1.xata.sh", got.Id)
+	})
+
+	t.Run("should refresh after invalidation", func(t *testing.T) {
+		svc.mu.Lock()
+		svc.regions["users"] = "eu-west-1"
+		svc.mu.Unlock()
+
+		router.Invalidate()
+		region, err := router.Region(context.TODO(), "users")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", region)
+	})
+}
+
+// blockingListClient holds the database lists until released, and counts them.
+type blockingListClient struct {
+	release chan struct{}
+	lists   *int32
+}
+
+func (c blockingListClient) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/dbs") && atomic.AddInt32(c.lists, 1) > 1 {
+		<-c.release
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func TestRouter_refresh(t *testing.T) {
+	svc := &regionsService{t: t, regions: map[string]string{"users": "us-east-1"}}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	var lists int32
+	release := make(chan struct{})
+	router, err := xata.NewRouter(time.Hour,
+		xata.WithBaseURL(testSrv.URL),
+		xata.WithAPIKey("test-key"),
+		xata.WithWorkspaceID("ws-id"),
+		xata.WithHTTPClient(blockingListClient{release: release, lists: &lists}),
+	)
+	assert.NoError(t, err)
+
+	_, err = router.Region(context.TODO(), "users")
+	assert.NoError(t, err)
+
+	svc.mu.Lock()
+	svc.regions["events"] = "eu-west-1"
+	svc.mu.Unlock()
+
+	// The lookups of a new database share a single list, held until released.
+	var wg sync.WaitGroup
+	regions := make([]string, 5)
+	for i := range regions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			regions[i], _ = router.Region(context.TODO(), "events")
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&lists) == 2 }, time.Second, time.Millisecond)
+
+	// The known databases are served meanwhile.
+	region, err := router.Region(context.TODO(), "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-1", region)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = router.Region(ctx, "events")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []string{"eu-west-1", "eu-west-1", "eu-west-1", "eu-west-1", "eu-west-1"}, regions)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lists))
+}
+
+func TestRouter_dataPlaneDomain(t *testing.T) {
+	svc := &regionsService{t: t, regions: map[string]string{"users": "us-east-1"}}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	target, err := url.Parse(testSrv.URL)
+	assert.NoError(t, err)
+
+	router, err := xata.NewRouter(time.Hour,
+		xata.WithBaseURL(testSrv.URL),
+		xata.WithAPIKey("test-key"),
+		xata.WithWorkspaceID("ws-id"),
+		xata.WithDataPlaneDomain("staging-xata.dev"),
+		xata.WithHTTPClient(hostRecorder{target: target}),
+	)
+	assert.NoError(t, err)
+
+	cli, err := xata.NewRecordsClient(xata.WithRouter(router), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.Get(context.TODO(), xata.GetRecordRequest{
+		RecordRequest: xata.RecordRequest{DatabaseName: xata.String("users"), BranchName: xata.String("main"), TableName: "t"},
+		RecordID:      "rec",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ws-id.us-east-1.staging-xata.dev", got.Id)
+}
+
+func TestRouter_credentials(t *testing.T) {
+	var writes atomic.Int32
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/workspaces/ws-id/dbs" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"databases": []map[string]string{{"name": "users", "region": "us-east-1"}}})
+			return
+		}
+		writes.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid API key"})
+	}))
+	defer testSrv.Close()
+
+	target, err := url.Parse(testSrv.URL)
+	assert.NoError(t, err)
+
+	provider := &rotatingCredentials{current: "old", next: "new"}
+	credentials := xata.WithCredentialsProvider(provider)
+	router, err := xata.NewRouter(time.Hour,
+		xata.WithBaseURL(testSrv.URL),
+		xata.WithWorkspaceID("ws-id"),
+		credentials,
+		xata.WithHTTPClient(hostRecorder{target: target}),
+	)
+	assert.NoError(t, err)
+
+	cli, err := xata.NewRecordsClient(xata.WithRouter(router), credentials)
+	assert.NoError(t, err)
+
+	_, err = cli.Get(context.TODO(), xata.GetRecordRequest{
+		RecordRequest: xata.RecordRequest{DatabaseName: xata.String("users"), BranchName: xata.String("main"), TableName: "t"},
+		RecordID:      "rec",
+	})
+	assert.Error(t, err)
+	// The rejected request is retried once, by the credentials of the client only.
+	assert.Equal(t, int32(2), writes.Load())
+	assert.Equal(t, []string{"old"}, provider.invalidated)
+}
+
+func TestNewRouter(t *testing.T) {
+	t.Setenv("XATA_WORKSPACE_ID", "")
+
+	_, err := xata.NewRouter(0, xata.WithAPIKey("test-key"))
+	assert.Error(t, err)
+}
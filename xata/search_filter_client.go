@@ -4,6 +4,7 @@ package xata
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
@@ -27,7 +28,11 @@ type BranchRequestOptional struct {
 }
 
 type searchAndFilterCli struct {
-	generated  xatagenworkspace.SearchAndFilterClient
+	generated xatagenworkspace.SearchAndFilterClient
+	// httpClient, baseURL and bearer send the AskStream requests.
+	httpClient httpClient
+	baseURL    string
+	bearer     string
 	dbName     string
 	branchName string
 }
@@ -72,12 +77,17 @@ func (s searchAndFilterCli) Query(ctx context.Context, request QueryTableRequest
 		return nil, err
 	}
 
+	var consistency *xatagenworkspace.QueryTableRequestConsistency
+	if request.Payload.Consistency != 0 {
+		consistency = (*xatagenworkspace.QueryTableRequestConsistency)(&request.Payload.Consistency)
+	}
+
 	return s.generated.QueryTable(ctx, dbBranchName, request.TableName, &xatagenworkspace.QueryTableRequest{
-		Filter:  (*xatagenworkspace.FilterExpression)(request.Payload.Filter),
-		Sort:    request.Payload.Sort,
-		Page:    (*xatagenworkspace.PageConfig)(request.Payload.Page),
-		Columns: &request.Payload.Columns,
-		// Consistency: (*xatagenworkspace.QueryTableRequestConsistency)(&request.Payload.Consistency),
+		Filter:      (*xatagenworkspace.FilterExpression)(request.Payload.Filter),
+		Sort:        request.Payload.Sort,
+		Page:        (*xatagenworkspace.PageConfig)(request.Payload.Page),
+		Columns:     &request.Payload.Columns,
+		Consistency: consistency,
 	})
 }
 
@@ -239,37 +249,41 @@ func (s searchAndFilterCli) Ask(ctx context.Context, request AskTableRequest) (*
 		return nil, err
 	}
 
+	return s.generated.AskTable(ctx, dbBranchName, request.TableName, constructAskTableRequest(request.Payload))
+}
+
+func constructAskTableRequest(payload AskTableRequestPayload) *xatagenworkspace.AskTableRequest {
 	var targetExpGen []*xatagenworkspace.TargetExpressionItem
 	var searchGen *xatagenworkspace.AskTableRequestSearch
-	if request.Payload.Search != nil {
+	if payload.Search != nil {
 		searchGen = &xatagenworkspace.AskTableRequestSearch{
-			Fuzziness: request.Payload.Search.Fuzziness,
+			Fuzziness: payload.Search.Fuzziness,
 			Target:    &targetExpGen,
 		}
 
-		if len(request.Payload.Search.Target) > 0 {
-			for _, e := range request.Payload.Search.Target {
+		if len(payload.Search.Target) > 0 {
+			for _, e := range payload.Search.Target {
 				targetExpGen = append(targetExpGen, (*xatagenworkspace.TargetExpressionItem)(e))
 			}
 		}
 	}
 
 	var vectorSearchGen *xatagenworkspace.AskTableRequestVectorSearch
-	if request.Payload.VectorSearch != nil {
+	if payload.VectorSearch != nil {
 		vectorSearchGen = &xatagenworkspace.AskTableRequestVectorSearch{
-			Column:        request.Payload.VectorSearch.Column,
-			ContentColumn: request.Payload.VectorSearch.ContentColumn,
-			Filter:        (*xatagenworkspace.FilterExpression)(request.Payload.VectorSearch.Filter),
+			Column:        payload.VectorSearch.Column,
+			ContentColumn: payload.VectorSearch.ContentColumn,
+			Filter:        (*xatagenworkspace.FilterExpression)(payload.VectorSearch.Filter),
 		}
 	}
 
-	return s.generated.AskTable(ctx, dbBranchName, request.TableName, &xatagenworkspace.AskTableRequest{
-		Question:     request.Payload.Question,
-		SearchType:   (*xatagenworkspace.AskTableRequestSearchType)(request.Payload.SearchType),
+	return &xatagenworkspace.AskTableRequest{
+		Question:     payload.Question,
+		SearchType:   (*xatagenworkspace.AskTableRequestSearchType)(payload.SearchType),
 		Search:       searchGen,
 		VectorSearch: vectorSearchGen,
-		Rules:        request.Payload.Rules,
-	})
+		Rules:        payload.Rules,
+	}
 }
 
 type AskFollowUpRequest struct {
@@ -367,18 +381,44 @@ func (s searchAndFilterCli) Aggregate(ctx context.Context, request AggregateTabl
 		return nil, err
 	}
 
+	return s.generated.AggregateTable(ctx, dbBranchName, request.TableName, constructAggregateTableRequest(request.Payload))
+}
+
+// AggregateResults runs aggregations with the client like Aggregate, and returns their raw
+// results, including the sub-aggregations of the buckets, with typed accessors.
+// The clients other than those of NewSearchAndFilterClient only return what the generated response
+// holds, without the sub-aggregations.
+func AggregateResults(ctx context.Context, client SearchAndFilterClient, request AggregateTableRequest) (*AggregateTableResponse, error) {
+	// The generated response drops the sub-aggregations of buckets, keep the raw response.
+	rec := &recordedResponse{}
+	resp, err := client.Aggregate(withRecordedResponse(ctx, rec), request)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := rec.body
+	if raw == nil {
+		if raw, err = json.Marshal(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return newAggregateTableResponse(resp, raw)
+}
+
+func constructAggregateTableRequest(payload AggregateTableRequestPayload) *xatagenworkspace.AggregateTableRequest {
 	var aggsGen xatagenworkspace.AggExpressionMap
-	if len(request.Payload.Aggregations) > 0 {
-		aggsGen = make(xatagenworkspace.AggExpressionMap, len(request.Payload.Aggregations))
-		for k, v := range request.Payload.Aggregations {
+	if len(payload.Aggregations) > 0 {
+		aggsGen = make(xatagenworkspace.AggExpressionMap, len(payload.Aggregations))
+		for k, v := range payload.Aggregations {
 			aggsGen[k] = v
 		}
 	}
 
-	return s.generated.AggregateTable(ctx, dbBranchName, request.TableName, &xatagenworkspace.AggregateTableRequest{
-		Filter: (*xatagenworkspace.FilterExpression)(request.Payload.Filter),
+	return &xatagenworkspace.AggregateTableRequest{
+		Filter: (*xatagenworkspace.FilterExpression)(payload.Filter),
 		Aggs:   &aggsGen,
-	})
+	}
 }
 
 // NewSearchAndFilterClient constructs a new search and filter client.
@@ -391,10 +431,13 @@ func NewSearchAndFilterClient(opts ...ClientOption) (SearchAndFilterClient, erro
 	return searchAndFilterCli{
 			generated: xatagenworkspace.NewSearchAndFilterClient(
 				func(options *xatagenclient.ClientOptions) {
-					options.HTTPClient = cliOpts.HTTPClient
+					options.HTTPClient = responseRecorder{next: cliOpts.HTTPClient}
 					options.BaseURL = cliOpts.BaseURL
 					options.Bearer = cliOpts.Bearer
 				}),
+			httpClient: cliOpts.HTTPClient,
+			baseURL:    cliOpts.BaseURL,
+			bearer:     cliOpts.Bearer,
 			dbName:     dbCfg.dbName,
 			branchName: dbCfg.branchName,
 		},
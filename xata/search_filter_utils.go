@@ -373,3 +373,20 @@ func NewNumericHistogramAggExpression(value NumericHistogramAgg) *xatagenworkspa
 		Interval: value.Interval,
 	}})
 }
+
+// Percentiles of the values in a numeric column.
+type PercentilesAgg struct {
+	// The column on which to compute the percentiles. Must be a numeric type.
+	Column string
+	// The percentiles to compute, between 0 and 100.
+	Percentiles []float64
+}
+
+func NewPercentilesAggExpression(value PercentilesAgg) *xatagenworkspace.AggExpression {
+	return xatagenworkspace.NewAggExpressionFromAggExpressionPercentiles(&xatagenworkspace.AggExpressionPercentiles{
+		Percentiles: &xatagenworkspace.PercentilesAgg{
+			Column:      value.Column,
+			Percentiles: value.Percentiles,
+		},
+	})
+}
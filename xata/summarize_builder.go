@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// defaultSummarizePageSize is the number of rows returned by summarize if no page size is set.
+	defaultSummarizePageSize = 20
+	// maxSummarizePageSize is the maximum number of rows returned by summarize.
+	maxSummarizePageSize = 1000
+)
+
+// SummarizeBuilder builds a SummarizeTableRequest.
+//
+//	request, err := xata.SummarizeTable("orders").
+//		GroupBy("customer").
+//		Count("orders", "*").
+//		Sum("revenue", "amount").
+//		OrderBy("revenue", xata.SortOrderDesc).
+//		PageSize(50).
+//		Build()
+type SummarizeBuilder struct {
+	request   SummarizeTableRequest
+	summaries []summary
+	sort      []map[string]SortOrder
+	pageSize  *int
+}
+
+type summary struct {
+	alias     string
+	operation string
+	column    string
+}
+
+// SummarizeTable starts building a summarize request for the given table.
+func SummarizeTable(tableName string) *SummarizeBuilder {
+	return &SummarizeBuilder{request: SummarizeTableRequest{TableName: tableName}}
+}
+
+// Database sets the database, the database of the client is used otherwise.
+func (s *SummarizeBuilder) Database(name string) *SummarizeBuilder {
+	s.request.DatabaseName = String(name)
+	return s
+}
+
+// Branch sets the branch, the branch of the client is used otherwise.
+func (s *SummarizeBuilder) Branch(name string) *SummarizeBuilder {
+	s.request.BranchName = String(name)
+	return s
+}
+
+// GroupBy adds columns to group the rows by.
+func (s *SummarizeBuilder) GroupBy(columns ...string) *SummarizeBuilder {
+	s.request.Payload.Columns = append(s.request.Payload.Columns, columns...)
+	return s
+}
+
+// Filter filters the records before summarizing them.
+func (s *SummarizeBuilder) Filter(filter *FilterExpression) *SummarizeBuilder {
+	s.request.Payload.Filter = filter
+	return s
+}
+
+// SummariesFilter filters the summarized rows, it can refer to the summary aliases.
+func (s *SummarizeBuilder) SummariesFilter(filter *FilterExpression) *SummarizeBuilder {
+	s.request.Payload.SummariesFilter = filter
+	return s
+}
+
+// Count counts the records of each group with a non-null value in column, or all records if
+// column is `*`.
+func (s *SummarizeBuilder) Count(alias, column string) *SummarizeBuilder {
+	return s.summary(alias, "count", column)
+}
+
+// Min adds the minimum value of a column in each group.
+func (s *SummarizeBuilder) Min(alias, column string) *SummarizeBuilder {
+	return s.summary(alias, "min", column)
+}
+
+// Max adds the maximum value of a column in each group.
+func (s *SummarizeBuilder) Max(alias, column string) *SummarizeBuilder {
+	return s.summary(alias, "max", column)
+}
+
+// Sum adds the sum of a column in each group.
+func (s *SummarizeBuilder) Sum(alias, column string) *SummarizeBuilder {
+	return s.summary(alias, "sum", column)
+}
+
+// Average adds the average of a column in each group.
+func (s *SummarizeBuilder) Average(alias, column string) *SummarizeBuilder {
+	return s.summary(alias, "average", column)
+}
+
+func (s *SummarizeBuilder) summary(alias, operation, column string) *SummarizeBuilder {
+	s.summaries = append(s.summaries, summary{alias: alias, operation: operation, column: column})
+	return s
+}
+
+// OrderBy sorts the rows by a group-by column or a summary alias.
+// The rows are sorted by the group-by columns if no order is given.
+func (s *SummarizeBuilder) OrderBy(column string, order SortOrder) *SummarizeBuilder {
+	s.sort = append(s.sort, map[string]SortOrder{column: order})
+	return s
+}
+
+// PageSize sets the number of rows returned, between 1 and 1000.
+func (s *SummarizeBuilder) PageSize(size int) *SummarizeBuilder {
+	s.pageSize = &size
+	return s
+}
+
+// Build validates and returns the request.
+func (s *SummarizeBuilder) Build() (SummarizeTableRequest, error) {
+	request := s.request
+
+	if request.TableName == "" {
+		return SummarizeTableRequest{}, fmt.Errorf("table name cannot be empty")
+	}
+
+	if len(request.Payload.Columns) == 0 && len(s.summaries) == 0 {
+		return SummarizeTableRequest{}, fmt.Errorf("at least a group-by column or a summary is required")
+	}
+
+	known := make(map[string]bool, len(request.Payload.Columns)+len(s.summaries))
+	for _, c := range request.Payload.Columns {
+		if c == "" {
+			return SummarizeTableRequest{}, fmt.Errorf("group-by column cannot be empty")
+		}
+		known[c] = true
+	}
+
+	if len(s.summaries) > 0 {
+		request.Payload.Summaries = make(map[string]map[string]any, len(s.summaries))
+	}
+	for _, sum := range s.summaries {
+		if sum.alias == "" {
+			return SummarizeTableRequest{}, fmt.Errorf("summary alias cannot be empty")
+		}
+		if sum.column == "" {
+			return SummarizeTableRequest{}, fmt.Errorf("summary %s: column cannot be empty", sum.alias)
+		}
+		if sum.column == "*" && sum.operation != "count" {
+			return SummarizeTableRequest{}, fmt.Errorf("summary %s: only count accepts `*`", sum.alias)
+		}
+		if known[sum.alias] {
+			return SummarizeTableRequest{}, fmt.Errorf("summary %s: alias is already used", sum.alias)
+		}
+		known[sum.alias] = true
+		request.Payload.Summaries[sum.alias] = map[string]any{sum.operation: sum.column}
+	}
+
+	sortList := s.sort
+	for _, sort := range sortList {
+		for column := range sort {
+			if !known[column] {
+				return SummarizeTableRequest{}, fmt.Errorf("cannot sort by %s, it is neither a group-by column nor a summary", column)
+			}
+		}
+	}
+	if len(sortList) == 0 {
+		for _, c := range request.Payload.Columns {
+			sortList = append(sortList, map[string]SortOrder{c: SortOrderAsc})
+		}
+	}
+	if len(sortList) > 0 {
+		request.Payload.Sort = NewSortExpressionFromStringSortOrderMapList(sortList)
+	}
+
+	if s.pageSize != nil {
+		if *s.pageSize < 1 || *s.pageSize > maxSummarizePageSize {
+			return SummarizeTableRequest{}, fmt.Errorf("page size must be between 1 and %d", maxSummarizePageSize)
+		}
+		request.Payload.NumberOfPage = Int(*s.pageSize)
+	}
+
+	return request, nil
+}
+
+// DecodeSummaries decodes the rows of a summarize response into out, which must be a pointer to
+// a slice. The fields are matched using their `json` tags, by group-by column or summary alias.
+func DecodeSummaries(rows []map[string]any, out any) error {
+	raw, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// SummarizeIterator walks the rows of a summarize request page by page.
+//
+// Unlike queries, summarize has no cursor: each page is fetched by requesting the rows of all the
+// previous pages plus one page, and only the new rows are returned. The rows must be sorted in a
+// stable order, which SummarizeBuilder ensures by default, and at most 1000 rows can be read.
+type SummarizeIterator struct {
+	client   SearchAndFilterClient
+	request  SummarizeTableRequest
+	pageSize int
+	seen     int
+	page     []map[string]any
+	done     bool
+	err      error
+}
+
+// NewSummarizeIterator returns an iterator over the rows of the request. The page size of the
+// request is used for each page, 20 rows if not set.
+func NewSummarizeIterator(client SearchAndFilterClient, request SummarizeTableRequest) *SummarizeIterator {
+	pageSize := defaultSummarizePageSize
+	if request.Payload.NumberOfPage != nil && *request.Payload.NumberOfPage > 0 {
+		pageSize = *request.Payload.NumberOfPage
+	}
+
+	return &SummarizeIterator{
+		client:   client,
+		request:  request,
+		pageSize: pageSize,
+	}
+}
+
+// Next fetches the next page. It returns false when all rows were read or an error occurred.
+func (s *SummarizeIterator) Next(ctx context.Context) bool {
+	if s.done || s.err != nil {
+		return false
+	}
+
+	size := s.seen + s.pageSize
+	if size >= maxSummarizePageSize {
+		size = maxSummarizePageSize
+		s.done = true
+	}
+
+	request := s.request
+	request.Payload.NumberOfPage = Int(size)
+
+	resp, err := s.client.Summarize(ctx, request)
+	if err != nil {
+		s.err = err
+		return false
+	}
+
+	rows := resp.Summaries
+	if len(rows) < size {
+		s.done = true
+	}
+	if len(rows) <= s.seen {
+		s.done = true
+		s.page = nil
+		return false
+	}
+
+	s.page = rows[s.seen:]
+	s.seen = len(rows)
+	return true
+}
+
+// Page returns the rows of the current page.
+func (s *SummarizeIterator) Page() []map[string]any {
+	return s.page
+}
+
+// Decode decodes the rows of the current page into out, see DecodeSummaries.
+func (s *SummarizeIterator) Decode(out any) error {
+	return DecodeSummaries(s.page, out)
+}
+
+// Err returns the error that stopped the iteration.
+func (s *SummarizeIterator) Err() error {
+	return s.err
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xataio/xata-go/xata"
+	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
+)
+
+func TestSummarizeBuilder_Build(t *testing.T) {
+	t.Run("should build a summarize request", func(t *testing.T) {
+		request, err := xata.SummarizeTable("orders").
+			Database("my-db").
+			Branch("dev").
+			GroupBy("customer").
+			Count("orders", "*").
+			Sum("revenue", "amount").
+			Average("avg", "amount").
+			OrderBy("revenue", xata.SortOrderDesc).
+			PageSize(50).
+			Build()
+		assert.NoError(t, err)
+
+		assert.Equal(t, "orders", request.TableName)
+		assert.Equal(t, "my-db", *request.DatabaseName)
+		assert.Equal(t, "dev", *request.BranchName)
+		assert.Equal(t, []string{"customer"}, request.Payload.Columns)
+		assert.Equal(t, map[string]map[string]any{
+			"orders":  {"count": "*"},
+			"revenue": {"sum": "amount"},
+			"avg":     {"average": "amount"},
+		}, request.Payload.Summaries)
+		assert.Equal(t, 50, *request.Payload.NumberOfPage)
+
+		sort, err := json.Marshal(request.Payload.Sort)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"revenue": "desc"}]`, string(sort))
+	})
+
+	t.Run("should sort by the group-by columns by default", func(t *testing.T) {
+		request, err := xata.SummarizeTable("orders").GroupBy("customer", "status").Build()
+		assert.NoError(t, err)
+
+		sort, err := json.Marshal(request.Payload.Sort)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"customer": "asc"}, {"status": "asc"}]`, string(sort))
+	})
+
+	invalid := map[string]*xata.SummarizeBuilder{
+		"empty table":          xata.SummarizeTable("").GroupBy("customer"),
+		"nothing to summarize": xata.SummarizeTable("orders"),
+		"empty group-by":       xata.SummarizeTable("orders").GroupBy(""),
+		"empty alias":          xata.SummarizeTable("orders").Count("", "*"),
+		"empty column":         xata.SummarizeTable("orders").Min("min", ""),
+		"star column":          xata.SummarizeTable("orders").Max("max", "*"),
+		"duplicated alias":     xata.SummarizeTable("orders").GroupBy("customer").Count("customer", "*"),
+		"unknown sort":         xata.SummarizeTable("orders").GroupBy("customer").OrderBy("amount", xata.SortOrderAsc),
+		"page size too small":  xata.SummarizeTable("orders").GroupBy("customer").PageSize(0),
+		"page size too large":  xata.SummarizeTable("orders").GroupBy("customer").PageSize(1001),
+	}
+
+	for name, builder := range invalid {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			_, err := builder.Build()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeSummaries(t *testing.T) {
+	type row struct {
+		Customer string  `json:"customer"`
+		Orders   int     `json:"orders"`
+		Revenue  float64 `json:"revenue"`
+	}
+
+	var rows []row
+	err := xata.DecodeSummaries([]map[string]any{
+		{"customer": "alice", "orders": 2.0, "revenue": 12.5},
+		{"customer": "bob", "orders": 1.0, "revenue": 3.0},
+	}, &rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []row{{"alice", 2, 12.5}, {"bob", 1, 3}}, rows)
+}
+
+type fakeSummarizeClient struct {
+	xata.SearchAndFilterClient
+	rows  []map[string]any
+	sizes []int
+	err   error
+}
+
+func (f *fakeSummarizeClient) Summarize(_ context.Context, request xata.SummarizeTableRequest) (*xatagenworkspace.SummarizeTableResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	size := *request.Payload.NumberOfPage
+	f.sizes = append(f.sizes, size)
+	if size > len(f.rows) {
+		size = len(f.rows)
+	}
+	return &xatagenworkspace.SummarizeTableResponse{Summaries: f.rows[:size]}, nil
+}
+
+func TestSummarizeIterator(t *testing.T) {
+	rows := make([]map[string]any, 5)
+	for i := range rows {
+		rows[i] = map[string]any{"customer": fmt.Sprintf("c%d", i)}
+	}
+
+	t.Run("should return each row once, page by page", func(t *testing.T) {
+		cli := &fakeSummarizeClient{rows: rows}
+		request, err := xata.SummarizeTable("orders").GroupBy("customer").PageSize(2).Build()
+		assert.NoError(t, err)
+
+		it := xata.NewSummarizeIterator(cli, request)
+
+		var got []string
+		for it.Next(context.TODO()) {
+			var page []struct {
+				Customer string `json:"customer"`
+			}
+			assert.NoError(t, it.Decode(&page))
+			for _, r := range page {
+				got = append(got, r.Customer)
+			}
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, []string{"c0", "c1", "c2", "c3", "c4"}, got)
+		assert.Equal(t, []int{2, 4, 6}, cli.sizes)
+	})
+
+	t.Run("should stop on a page that is exactly full", func(t *testing.T) {
+		cli := &fakeSummarizeClient{rows: rows[:4]}
+		request, err := xata.SummarizeTable("orders").GroupBy("customer").PageSize(2).Build()
+		assert.NoError(t, err)
+
+		it := xata.NewSummarizeIterator(cli, request)
+		pages := 0
+		for it.Next(context.TODO()) {
+			pages++
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, 2, pages)
+		assert.Equal(t, []int{2, 4, 6}, cli.sizes)
+	})
+
+	t.Run("should stop at the maximum page size", func(t *testing.T) {
+		many := make([]map[string]any, 1200)
+		cli := &fakeSummarizeClient{rows: many}
+		request, err := xata.SummarizeTable("orders").GroupBy("customer").PageSize(600).Build()
+		assert.NoError(t, err)
+
+		it := xata.NewSummarizeIterator(cli, request)
+		read := 0
+		for it.Next(context.TODO()) {
+			read += len(it.Page())
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, 1000, read)
+		assert.Equal(t, []int{600, 1000}, cli.sizes)
+	})
+
+	t.Run("should return the error", func(t *testing.T) {
+		cli := &fakeSummarizeClient{err: errors.New("boom")}
+		it := xata.NewSummarizeIterator(cli, xata.SummarizeTableRequest{TableName: "orders"})
+		assert.False(t, it.Next(context.TODO()))
+		assert.EqualError(t, it.Err(), "boom")
+	})
+}
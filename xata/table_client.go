@@ -17,6 +17,10 @@ type TableClient interface {
 	DeleteColumn(ctx context.Context, request DeleteColumnRequest) (*xatagenworkspace.DeleteColumnResponse, error)
 	GetSchema(ctx context.Context, request TableRequest) (*xatagenworkspace.GetTableSchemaResponse, error)
 	GetColumns(ctx context.Context, request TableRequest) (*xatagenworkspace.GetTableColumnsResponse, error)
+	Rename(ctx context.Context, request RenameTableRequest) (*xatagenworkspace.UpdateTableResponse, error)
+	GetColumn(ctx context.Context, request ColumnRequest) (*xatagenworkspace.Column, error)
+	RenameColumn(ctx context.Context, request RenameColumnRequest) (*xatagenworkspace.UpdateColumnResponse, error)
+	SetSchema(ctx context.Context, request SetTableSchemaRequest) (*xatagenworkspace.SetTableSchemaResponse, error)
 }
 
 type tableClient struct {
@@ -25,16 +29,22 @@ type tableClient struct {
 	branchName string
 }
 
-func (t tableClient) dbBranchName(request TableRequest) string {
+func (t tableClient) dbBranchName(request TableRequest) (string, error) {
 	if request.DatabaseName == nil {
+		if t.dbName == "" {
+			return "", fmt.Errorf("database name cannot be empty")
+		}
 		request.DatabaseName = String(t.dbName)
 	}
 
 	if request.BranchName == nil {
+		if t.branchName == "" {
+			return "", fmt.Errorf("branch name cannot be empty")
+		}
 		request.BranchName = String(t.branchName)
 	}
 
-	return fmt.Sprintf("%s:%s", *request.DatabaseName, *request.BranchName)
+	return fmt.Sprintf("%s:%s", *request.DatabaseName, *request.BranchName), nil
 }
 
 type TableRequest struct {
@@ -44,11 +54,40 @@ type TableRequest struct {
 }
 
 func (t tableClient) Create(ctx context.Context, request TableRequest) (*xatagenworkspace.CreateTableResponse, error) {
-	return t.generated.CreateTable(ctx, t.dbBranchName(request), request.TableName)
+	dbBranchName, err := t.dbBranchName(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.CreateTable(ctx, dbBranchName, request.TableName)
 }
 
 func (t tableClient) Delete(ctx context.Context, request TableRequest) (*xatagenworkspace.DeleteTableResponse, error) {
-	return t.generated.DeleteTable(ctx, t.dbBranchName(request), request.TableName)
+	dbBranchName, err := t.dbBranchName(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.DeleteTable(ctx, dbBranchName, request.TableName)
+}
+
+type RenameTableRequest struct {
+	TableRequest
+	NewName string
+}
+
+// Rename renames a table.
+// https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name#update-table
+func (t tableClient) Rename(ctx context.Context, request RenameTableRequest) (*xatagenworkspace.UpdateTableResponse, error) {
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+	if request.NewName == "" {
+		return nil, fmt.Errorf("new table name cannot be empty")
+	}
+
+	return t.generated.UpdateTable(ctx, dbBranchName, request.TableName, &xatagenworkspace.UpdateTableRequest{Name: request.NewName})
 }
 
 type ColumnType xatagenworkspace.ColumnType
@@ -97,11 +136,16 @@ type AddColumnRequest struct {
 // AddColumn creates a new column.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/columns#create-new-column
 func (t tableClient) AddColumn(ctx context.Context, request AddColumnRequest) (*xatagenworkspace.AddTableColumnResponse, error) {
-	return t.generated.AddTableColumn(ctx, t.dbBranchName(request.TableRequest), request.TableName, copyColumn(*request.Column))
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.AddTableColumn(ctx, dbBranchName, request.TableName, copyColumn(*request.Column))
 }
 
 func copyColumn(in Column) *xatagenworkspace.Column {
-	return &xatagenworkspace.Column{
+	out := &xatagenworkspace.Column{
 		Name:         in.Name,
 		Type:         (xatagenworkspace.ColumnType)(in.Type),
 		Link:         (*xatagenworkspace.ColumnLink)(in.Link),
@@ -111,8 +155,18 @@ func copyColumn(in Column) *xatagenworkspace.Column {
 		NotNull:      in.NotNull,
 		DefaultValue: in.DefaultValue,
 		Unique:       in.Unique,
-		// Columns: &[]*xatagenworkspace.Column{}, TODO learn usage
 	}
+
+	// Object columns hold their nested columns.
+	if in.Columns != nil {
+		columns := make([]*xatagenworkspace.Column, 0, len(*in.Columns))
+		for _, c := range *in.Columns {
+			columns = append(columns, copyColumn(*c))
+		}
+		out.Columns = &columns
+	}
+
+	return out
 }
 
 type DeleteColumnRequest struct {
@@ -123,19 +177,102 @@ type DeleteColumnRequest struct {
 // DeleteColumn deletes a column.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/columns/column_name#delete-column
 func (t tableClient) DeleteColumn(ctx context.Context, request DeleteColumnRequest) (*xatagenworkspace.DeleteColumnResponse, error) {
-	return t.generated.DeleteColumn(ctx, t.dbBranchName(request.TableRequest), request.TableName, request.ColumnName)
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.DeleteColumn(ctx, dbBranchName, request.TableName, request.ColumnName)
+}
+
+type ColumnRequest struct {
+	TableRequest
+	ColumnName string
+}
+
+// GetColumn gets the definition of a column.
+// https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/columns/column_name#get-column-information
+func (t tableClient) GetColumn(ctx context.Context, request ColumnRequest) (*xatagenworkspace.Column, error) {
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+	if request.ColumnName == "" {
+		return nil, fmt.Errorf("column name cannot be empty")
+	}
+
+	return t.generated.GetColumn(ctx, dbBranchName, request.TableName, request.ColumnName)
+}
+
+type RenameColumnRequest struct {
+	TableRequest
+	ColumnName string
+	NewName    string
+}
+
+// RenameColumn renames a column. Columns of an object column are addressed with the dot
+// notation, e.g. "address.city".
+// https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/columns/column_name#update-column
+func (t tableClient) RenameColumn(ctx context.Context, request RenameColumnRequest) (*xatagenworkspace.UpdateColumnResponse, error) {
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+	if request.ColumnName == "" {
+		return nil, fmt.Errorf("column name cannot be empty")
+	}
+	if request.NewName == "" {
+		return nil, fmt.Errorf("new column name cannot be empty")
+	}
+
+	return t.generated.UpdateColumn(ctx, dbBranchName, request.TableName, request.ColumnName, &xatagenworkspace.UpdateColumnRequest{Name: request.NewName})
 }
 
 // GetSchema gets the schema of a table.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/schema#get-table-schema
 func (t tableClient) GetSchema(ctx context.Context, request TableRequest) (*xatagenworkspace.GetTableSchemaResponse, error) {
-	return t.generated.GetTableSchema(ctx, t.dbBranchName(request), request.TableName)
+	dbBranchName, err := t.dbBranchName(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.GetTableSchema(ctx, dbBranchName, request.TableName)
+}
+
+type SetTableSchemaRequest struct {
+	TableRequest
+	Columns []*Column
+}
+
+// SetSchema replaces the columns of a table: missing columns are created and columns not in
+// the request are deleted.
+// https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/schema#update-table-schema
+func (t tableClient) SetSchema(ctx context.Context, request SetTableSchemaRequest) (*xatagenworkspace.SetTableSchemaResponse, error) {
+	dbBranchName, err := t.dbBranchName(request.TableRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*xatagenworkspace.Column, 0, len(request.Columns))
+	for _, c := range request.Columns {
+		if c == nil {
+			return nil, fmt.Errorf("column cannot be nil")
+		}
+		columns = append(columns, copyColumn(*c))
+	}
+
+	return t.generated.SetTableSchema(ctx, dbBranchName, request.TableName, &xatagenworkspace.SetTableSchemaRequest{Columns: columns})
 }
 
 // GetColumns retrieves the list of table columns and their definition.
 // https://xata.io/docs/api-reference/db/db_branch_name/tables/table_name/columns#list-table-columns
 func (t tableClient) GetColumns(ctx context.Context, request TableRequest) (*xatagenworkspace.GetTableColumnsResponse, error) {
-	return t.generated.GetTableColumns(ctx, t.dbBranchName(request), request.TableName)
+	dbBranchName, err := t.dbBranchName(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.generated.GetTableColumns(ctx, dbBranchName, request.TableName)
 }
 
 // NewTableClient constructs a client for interacting with tables.
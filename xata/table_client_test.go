@@ -4,7 +4,9 @@ package xata_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	xatagenworkspace "github.com/xataio/xata-go/xata/internal/fern-workspace/generated/go"
@@ -438,3 +440,153 @@ func Test_tableClient_GetColumns(t *testing.T) {
 		})
 	}
 }
+
+func Test_tableClient_Rename(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagenworkspace.UpdateTableResponse
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should rename a table",
+			want: &xatagenworkspace.UpdateTableResponse{
+				MigrationId:       "mig_1",
+				ParentMigrationId: "mig_0",
+				Status:            xatagenworkspace.MigrationStatusCompleted,
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, eTC := range errTestCasesWorkspace {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodPatch, "/db/db-name:main/tables/table-name", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewTableClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+			assert.NoError(err)
+
+			got, err := cli.Rename(context.TODO(), xata.RenameTableRequest{
+				TableRequest: xata.TableRequest{DatabaseName: xata.String("db-name"), TableName: "table-name"},
+				NewName:      "new-table-name",
+			})
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_tableClient_GetColumn(t *testing.T) {
+	want := &xatagenworkspace.Column{Name: "email", Type: xatagenworkspace.ColumnTypeEmail, Unique: xata.Bool(true)}
+	testSrv := testService(t, http.MethodGet, "/db/db-name:main/tables/table-name/columns/email", http.StatusOK, false, want)
+	defer testSrv.Close()
+
+	cli, err := xata.NewTableClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.GetColumn(context.TODO(), xata.ColumnRequest{
+		TableRequest: xata.TableRequest{DatabaseName: xata.String("db-name"), TableName: "table-name"},
+		ColumnName:   "email",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_tableClient_RenameColumn(t *testing.T) {
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/db/db-name:main/tables/table-name/columns/address.city", r.URL.Path)
+
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"name": "town"}, body)
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"migrationID": "mig_1", "status": "completed"})
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewTableClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.RenameColumn(context.TODO(), xata.RenameColumnRequest{
+		TableRequest: xata.TableRequest{DatabaseName: xata.String("db-name"), TableName: "table-name"},
+		ColumnName:   "address.city",
+		NewName:      "town",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "mig_1", got.MigrationId)
+
+	_, err = cli.RenameColumn(context.TODO(), xata.RenameColumnRequest{
+		TableRequest: xata.TableRequest{DatabaseName: xata.String("db-name"), TableName: "table-name"},
+		ColumnName:   "address.city",
+	})
+	assert.EqualError(t, err, "new column name cannot be empty")
+}
+
+func Test_tableClient_SetSchema(t *testing.T) {
+	testSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/db/db-name:main/tables/table-name/schema", r.URL.Path)
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"columns": []any{
+			map[string]any{"name": "name", "type": "string", "notNull": true, "defaultValue": "anonymous"},
+			map[string]any{"name": "address", "type": "object", "columns": []any{
+				map[string]any{"name": "city", "type": "string"},
+			}},
+		}}, body)
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"migrationID": "mig_1", "status": "completed"})
+	}))
+	defer testSrv.Close()
+
+	cli, err := xata.NewTableClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	got, err := cli.SetSchema(context.TODO(), xata.SetTableSchemaRequest{
+		TableRequest: xata.TableRequest{DatabaseName: xata.String("db-name"), TableName: "table-name"},
+		Columns: []*xata.Column{
+			{Name: "name", Type: xata.ColumnTypeString, NotNull: xata.Bool(true), DefaultValue: xata.String("anonymous")},
+			{Name: "address", Type: xata.ColumnTypeObject, Columns: &[]*xata.Column{
+				{Name: "city", Type: xata.ColumnTypeString},
+			}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "mig_1", got.MigrationId)
+}
+
+func Test_tableClient_dbBranchName(t *testing.T) {
+	cli, err := xata.NewTableClient(xata.WithBaseURL("http://localhost"), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	_, err = cli.Create(context.TODO(), xata.TableRequest{TableName: "table-name"})
+	assert.EqualError(t, err, "database name cannot be empty")
+
+	_, err = cli.GetColumns(context.TODO(), xata.TableRequest{TableName: "table-name"})
+	assert.EqualError(t, err, "database name cannot be empty")
+}
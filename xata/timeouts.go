@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Operation is a kind of request whose timeout can be set apart from the default timeout.
+type Operation string
+
+const (
+	// OperationDefault is every request not covered by another operation.
+	OperationDefault Operation = ""
+	// OperationAsk is a question to a table, answered by a model: Ask, AskFollowUp and AskStream.
+	OperationAsk Operation = "ask"
+	// OperationBulkInsert is BulkInsert.
+	OperationBulkInsert Operation = "bulk-insert"
+	// OperationFileUpload is the upload of a file to a file or file[] column.
+	OperationFileUpload Operation = "file-upload"
+)
+
+// defaultOperationTimeouts are the timeouts of the slow operations when a default timeout is
+// set. They're never shorter than the default timeout.
+var defaultOperationTimeouts = map[Operation]time.Duration{
+	OperationAsk:        2 * time.Minute,
+	OperationBulkInsert: 2 * time.Minute,
+	OperationFileUpload: 5 * time.Minute,
+}
+
+// WithDefaultTimeout sets the deadline of every request, including reading the response.
+// The ask, bulk insert and file upload requests get a longer timeout, see WithOperationTimeout.
+// The deadline of the context is kept when it's earlier.
+// When the HTTP client retries, e.g. a retryablehttp client, all the attempts share the
+// deadline.
+func WithDefaultTimeout(timeout time.Duration) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithOperationTimeout sets the timeout of an operation, overriding the default timeout.
+// A zero timeout disables the timeout of the operation.
+func WithOperationTimeout(operation Operation, timeout time.Duration) func(options *ClientOptions) {
+	return func(options *ClientOptions) {
+		if options.OperationTimeouts == nil {
+			options.OperationTimeouts = map[Operation]time.Duration{}
+		}
+		options.OperationTimeouts[operation] = timeout
+	}
+}
+
+// ErrTimeout is matched by the errors of the requests that ran out of time, see IsTimeout.
+var ErrTimeout = errors.New("request timed out")
+
+// TimeoutError is returned when a request didn't complete within its timeout.
+type TimeoutError struct {
+	Operation Operation
+	Timeout   time.Duration
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Operation == OperationDefault {
+		return fmt.Sprintf("request timed out after %s: %v", e.Timeout, e.Err)
+	}
+	return fmt.Sprintf("%s request timed out after %s: %v", e.Operation, e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// IsTimeout tells whether a request failed because it ran out of time, either because of
+// the client timeouts or the deadline of its context, rather than because of the server.
+func IsTimeout(err error) bool {
+	if errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// timeoutClient sets the deadline of the operation on every request.
+type timeoutClient struct {
+	next              httpClient
+	timeout           time.Duration
+	operationTimeouts map[Operation]time.Duration
+}
+
+func newTimeoutClient(cliOpts *ClientOptions) httpClient {
+	if cliOpts.Timeout <= 0 && len(cliOpts.OperationTimeouts) == 0 {
+		return cliOpts.HTTPClient
+	}
+
+	return timeoutClient{
+		next:              cliOpts.HTTPClient,
+		timeout:           cliOpts.Timeout,
+		operationTimeouts: cliOpts.OperationTimeouts,
+	}
+}
+
+func (c timeoutClient) timeoutOf(operation Operation) time.Duration {
+	if timeout, ok := c.operationTimeouts[operation]; ok {
+		return timeout
+	}
+	if c.timeout <= 0 {
+		return 0
+	}
+	return max(c.timeout, defaultOperationTimeouts[operation])
+}
+
+func (c timeoutClient) Do(req *http.Request) (*http.Response, error) {
+	operation := operationOf(req)
+	timeout := c.timeoutOf(operation)
+	if timeout <= 0 {
+		return c.next.Do(req)
+	}
+
+	parent := req.Context()
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	resp, err := c.next.Do(req.WithContext(ctx))
+	if err != nil {
+		if timedOut(parent, ctx) {
+			err = &TimeoutError{Operation: operation, Timeout: timeout, Err: err}
+		}
+		cancel()
+		return nil, err
+	}
+
+	// The deadline covers reading the body, it's released when the body is closed.
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, parent: parent, ctx: ctx, cancel: cancel, operation: operation, timeout: timeout}
+	return resp, nil
+}
+
+// timedOut tells whether the deadline of ctx set by the client fired, rather than the deadline
+// or the cancellation of the parent context, which are reported as they are.
+func timedOut(parent, ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded && parent.Err() == nil
+}
+
+type timeoutBody struct {
+	io.ReadCloser
+	parent    context.Context
+	ctx       context.Context
+	cancel    context.CancelFunc
+	operation Operation
+	timeout   time.Duration
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && timedOut(b.parent, b.ctx) {
+		err = &TimeoutError{Operation: b.operation, Timeout: b.timeout, Err: err}
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// operationOf tells the operation of a workspace API request from its method and path, e.g.
+// POST /db/{db_branch_name}/tables/{table_name}/bulk.
+func operationOf(req *http.Request) Operation {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	// The segments after /tables/{table_name}.
+	var rest []string
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "tables" {
+			rest = segments[i+2:]
+			break
+		}
+	}
+	if len(rest) == 0 {
+		return OperationDefault
+	}
+
+	switch req.Method {
+	case http.MethodPost:
+		if rest[0] == "ask" {
+			return OperationAsk
+		}
+		if len(rest) == 1 && rest[0] == "bulk" {
+			return OperationBulkInsert
+		}
+	case http.MethodPut:
+		// data/{record_id}/column/{column_name}/file[/{file_id}]
+		if len(rest) >= 5 && rest[0] == "data" && rest[2] == "column" && rest[4] == "file" {
+			return OperationFileUpload
+		}
+	}
+
+	return OperationDefault
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// slowService answers after the delay of the path, or fails with a server error.
+type slowService struct {
+	delays     map[string]time.Duration
+	stallBody  bool
+	serverFail bool
+}
+
+func (s slowService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wait := func(d time.Duration) {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+	}
+
+	if s.serverFail {
+		wait(s.delays[r.URL.Path])
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "internal error"})
+		return
+	}
+
+	if s.stallBody {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":`))
+		w.(http.Flusher).Flush()
+		wait(s.delays[r.URL.Path])
+		_, _ = w.Write([]byte(`"rec"}`))
+		return
+	}
+
+	wait(s.delays[r.URL.Path])
+	if r.URL.Path == "/db/db:main/tables/t/bulk" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"records": []any{}})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id": "rec"})
+}
+
+// retryingClient sends every request again on server errors, as long as its context allows.
+type retryingClient struct {
+	attempts *int32
+}
+
+func (c retryingClient) Do(req *http.Request) (*http.Response, error) {
+	for {
+		atomic.AddInt32(c.attempts, 1)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode < http.StatusInternalServerError {
+			return resp, err
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	getRequest := xata.GetRecordRequest{
+		RecordRequest: xata.RecordRequest{DatabaseName: xata.String("db"), BranchName: xata.String("main"), TableName: "t"},
+		RecordID:      "rec",
+	}
+	bulkRequest := xata.BulkInsertRecordRequest{
+		RecordRequest: getRequest.RecordRequest,
+		Records:       []map[string]*xata.DataInputRecordValue{{"name": xata.ValueFromString("a")}},
+	}
+
+	newClient := func(t *testing.T, svc slowService, opts ...xata.ClientOption) xata.RecordsClient {
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		cli, err := xata.NewRecordsClient(append([]xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key")}, opts...)...)
+		assert.NoError(t, err)
+		return cli
+	}
+
+	t.Run("should time out a slow request", func(t *testing.T) {
+		cli := newClient(t, slowService{delays: map[string]time.Duration{"/db/db:main/tables/t/data/rec": 300 * time.Millisecond}},
+			xata.WithDefaultTimeout(50*time.Millisecond))
+
+		start := time.Now()
+		_, err := cli.Get(context.TODO(), getRequest)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.True(t, xata.IsTimeout(err))
+		assert.ErrorIs(t, err, xata.ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var timeoutErr *xata.TimeoutError
+		assert.True(t, errors.As(err, &timeoutErr))
+		assert.Equal(t, xata.OperationDefault, timeoutErr.Operation)
+		assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+	})
+
+	t.Run("should time out reading the response", func(t *testing.T) {
+		cli := newClient(t, slowService{stallBody: true, delays: map[string]time.Duration{"/db/db:main/tables/t/data/rec": 300 * time.Millisecond}},
+			xata.WithDefaultTimeout(50*time.Millisecond))
+
+		_, err := cli.Get(context.TODO(), getRequest)
+		assert.ErrorIs(t, err, xata.ErrTimeout)
+	})
+
+	t.Run("should give the slow operations more time", func(t *testing.T) {
+		cli := newClient(t, slowService{delays: map[string]time.Duration{"/db/db:main/tables/t/bulk": 100 * time.Millisecond}},
+			xata.WithDefaultTimeout(50*time.Millisecond))
+
+		_, err := cli.BulkInsert(context.TODO(), bulkRequest)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should apply the operation overrides", func(t *testing.T) {
+		cli := newClient(t, slowService{delays: map[string]time.Duration{"/db/db:main/tables/t/bulk": 300 * time.Millisecond}},
+			xata.WithDefaultTimeout(time.Minute),
+			xata.WithOperationTimeout(xata.OperationBulkInsert, 50*time.Millisecond))
+
+		_, err := cli.BulkInsert(context.TODO(), bulkRequest)
+		var timeoutErr *xata.TimeoutError
+		assert.True(t, errors.As(err, &timeoutErr))
+		assert.Equal(t, xata.OperationBulkInsert, timeoutErr.Operation)
+		assert.Contains(t, err.Error(), "bulk-insert request timed out after 50ms")
+	})
+
+	t.Run("should share the deadline across retries", func(t *testing.T) {
+		var attempts int32
+		cli := newClient(t, slowService{serverFail: true, delays: map[string]time.Duration{"/db/db:main/tables/t/data/rec": 40 * time.Millisecond}},
+			xata.WithHTTPClient(retryingClient{attempts: &attempts}),
+			xata.WithDefaultTimeout(100*time.Millisecond))
+
+		start := time.Now()
+		_, err := cli.Get(context.TODO(), getRequest)
+		assert.ErrorIs(t, err, xata.ErrTimeout)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Greater(t, atomic.LoadInt32(&attempts), int32(1))
+	})
+
+	t.Run("should keep an earlier context deadline", func(t *testing.T) {
+		cli := newClient(t, slowService{delays: map[string]time.Duration{"/db/db:main/tables/t/data/rec": 300 * time.Millisecond}},
+			xata.WithDefaultTimeout(time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := cli.Get(ctx, getRequest)
+		assert.True(t, xata.IsTimeout(err))
+		assert.Less(t, time.Since(start), time.Second)
+
+		// The deadline of the context isn't the one of the client.
+		var timeoutErr *xata.TimeoutError
+		assert.False(t, errors.As(err, &timeoutErr))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should not report cancellations as timeouts", func(t *testing.T) {
+		cli := newClient(t, slowService{delays: map[string]time.Duration{"/db/db:main/tables/t/data/rec": 300 * time.Millisecond}},
+			xata.WithDefaultTimeout(time.Minute))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		_, err := cli.Get(ctx, getRequest)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, xata.IsTimeout(err))
+	})
+
+	t.Run("should not report server errors as timeouts", func(t *testing.T) {
+		cli := newClient(t, slowService{serverFail: true}, xata.WithDefaultTimeout(time.Minute))
+
+		_, err := cli.Get(context.TODO(), getRequest)
+		assert.Error(t, err)
+		assert.False(t, xata.IsTimeout(err))
+	})
+}
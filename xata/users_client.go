@@ -4,13 +4,34 @@ package xata
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"strings"
 
 	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
 	xatagenclient "github.com/xataio/xata-go/xata/internal/fern-core/generated/go/core"
 )
 
+// UpdateUserRequest changes the profile of the user. Fields left nil keep their current value.
+type UpdateUserRequest struct {
+	Email    *string
+	Fullname *string
+	// Image is the URL of the avatar of the user, an empty string removes it.
+	Image *string
+}
+
+type DeleteUserRequest struct {
+	// ConfirmDeletion must be true, the user account and its data can't be recovered.
+	ConfirmDeletion bool
+	// Email, when set, must be the email of the user of the API key, to avoid deleting
+	// another account than intended.
+	Email string
+}
+
 type UsersClient interface {
 	Get(ctx context.Context) (*xatagencore.UserWithId, error)
+	Update(ctx context.Context, request UpdateUserRequest) (*xatagencore.UserWithId, error)
+	Delete(ctx context.Context, request DeleteUserRequest) error
 }
 
 type usersCli struct {
@@ -23,6 +44,64 @@ func (u usersCli) Get(ctx context.Context) (*xatagencore.UserWithId, error) {
 	return u.generated.GetUser(ctx)
 }
 
+// Update updates the profile of the user making the request.
+// https://xata.io/docs/api-reference/user#update-user-info
+func (u usersCli) Update(ctx context.Context, request UpdateUserRequest) (*xatagencore.UserWithId, error) {
+	if request.Email == nil && request.Fullname == nil && request.Image == nil {
+		return nil, fmt.Errorf("nothing to update")
+	}
+	if request.Email != nil && !strings.Contains(*request.Email, "@") {
+		return nil, fmt.Errorf("invalid email: %q", *request.Email)
+	}
+	if request.Fullname != nil && strings.TrimSpace(*request.Fullname) == "" {
+		return nil, fmt.Errorf("full name cannot be empty")
+	}
+	if request.Image != nil && *request.Image != "" {
+		if imageURL, err := url.Parse(*request.Image); err != nil || !imageURL.IsAbs() {
+			return nil, fmt.Errorf("invalid image URL: %q", *request.Image)
+		}
+	}
+
+	// The API replaces the whole profile, so the unchanged fields are sent as they are.
+	current, err := u.generated.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &xatagencore.User{Email: current.Email, Fullname: current.Fullname, Image: current.Image}
+	if request.Email != nil {
+		user.Email = *request.Email
+	}
+	if request.Fullname != nil {
+		user.Fullname = *request.Fullname
+	}
+	if request.Image != nil {
+		user.Image = *request.Image
+	}
+
+	return u.generated.UpdateUser(ctx, user)
+}
+
+// Delete permanently deletes the user making the request.
+// https://xata.io/docs/api-reference/user#delete-user
+func (u usersCli) Delete(ctx context.Context, request DeleteUserRequest) error {
+	if !request.ConfirmDeletion {
+		return fmt.Errorf("user deletion must be confirmed with ConfirmDeletion")
+	}
+
+	if request.Email != "" {
+		current, err := u.generated.GetUser(ctx)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(current.Email, request.Email) {
+			return fmt.Errorf("the API key belongs to %s, not %s", current.Email, request.Email)
+		}
+	}
+
+	return u.generated.DeleteUser(ctx)
+}
+
 // NewUsersClient constructs a client for interacting users.
 func NewUsersClient(opts ...ClientOption) (UsersClient, error) {
 	cliOpts, err := consolidateClientOptionsForCore(opts...)
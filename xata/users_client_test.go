@@ -4,7 +4,9 @@ package xata_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -77,3 +79,108 @@ func Test_usersCli_Get(t *testing.T) {
 		})
 	}
 }
+
+// userService serves the user endpoint, keeping the profile between requests.
+func userService(t *testing.T, user *xatagen.UserWithId, deleted *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/user", r.URL.Path)
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(user))
+		case http.MethodDelete:
+			*deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(user))
+	}))
+}
+
+func Test_usersCli_Update(t *testing.T) {
+	user := &xatagen.UserWithId{Id: "some-id", Email: "email@test.com", Fullname: "name lastname", Image: "https://example.com/a.png"}
+	testSrv := userService(t, user, nil)
+	defer testSrv.Close()
+
+	cli, err := xata.NewUsersClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+	assert.NoError(t, err)
+
+	t.Run("should only change the given fields", func(t *testing.T) {
+		got, err := cli.Update(context.TODO(), xata.UpdateUserRequest{Fullname: xata.String("new name")})
+		assert.NoError(t, err)
+		assert.Equal(t, &xatagen.UserWithId{Id: "some-id", Email: "email@test.com", Fullname: "new name", Image: "https://example.com/a.png"}, got)
+
+		noImage := ""
+		got, err = cli.Update(context.TODO(), xata.UpdateUserRequest{Image: &noImage})
+		assert.NoError(t, err)
+		assert.Equal(t, "", got.Image)
+		assert.Equal(t, "new name", got.Fullname)
+	})
+
+	invalid := map[string]xata.UpdateUserRequest{
+		"no changes":    {},
+		"invalid email": {Email: xata.String("email")},
+		"blank name":    {Fullname: xata.String(" ")},
+		"relative URL":  {Image: xata.String("a.png")},
+	}
+	for name, request := range invalid {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			_, err := cli.Update(context.TODO(), request)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_usersCli_Delete(t *testing.T) {
+	user := &xatagen.UserWithId{Id: "some-id", Email: "email@test.com"}
+
+	tests := []struct {
+		name        string
+		request     xata.DeleteUserRequest
+		wantErr     bool
+		wantDeleted bool
+	}{
+		{
+			name:    "should require a confirmation",
+			request: xata.DeleteUserRequest{Email: "email@test.com"},
+			wantErr: true,
+		},
+		{
+			name:    "should refuse to delete another user",
+			request: xata.DeleteUserRequest{ConfirmDeletion: true, Email: "other@test.com"},
+			wantErr: true,
+		},
+		{
+			name:        "should delete the user",
+			request:     xata.DeleteUserRequest{ConfirmDeletion: true, Email: "Email@test.com"},
+			wantDeleted: true,
+		},
+		{
+			name:        "should delete the user without checking the email",
+			request:     xata.DeleteUserRequest{ConfirmDeletion: true},
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleted bool
+			testSrv := userService(t, user, &deleted)
+			defer testSrv.Close()
+
+			cli, err := xata.NewUsersClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"))
+			assert.NoError(t, err)
+
+			err = cli.Delete(context.TODO(), tt.request)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDeleted, deleted)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/url"
 	"os"
 	"strings"
@@ -20,6 +21,9 @@ const (
 	EnvXataWorkspaceID = "XATA_WORKSPACE_ID"
 	EnvXataBranch      = "XATA_BRANCH"
 	EnvXataRegion      = "XATA_REGION"
+	// EnvXataDataPlaneDomain is the domain of the workspace API, e.g. for a staging or a private
+	// endpoint.
+	EnvXataDataPlaneDomain = "XATA_DATA_PLANE_DOMAIN"
 )
 
 const (
@@ -98,33 +102,80 @@ type databaseConfig struct {
 	dbName          string
 	branchName      string
 	domainWorkspace string
+	// scheme and port of the data plane, kept from the database URL.
+	scheme string
+	port   string
+	// customURL is the data plane URL of a database URL whose host isn't
+	// {workspace_id}.{region}.{domain}, e.g. a local emulator or a proxy. It serves every region.
+	customURL string
+}
+
+// dataPlaneURL returns the base URL of the workspace API for a region.
+func (c databaseConfig) dataPlaneURL(region string) string {
+	if c.customURL != "" {
+		return c.customURL
+	}
+
+	scheme := c.scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	host := fmt.Sprintf("%s.%s.%s", c.workspaceID, region, c.domainWorkspace)
+	if c.port != "" {
+		host = net.JoinHostPort(host, c.port)
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, host)
 }
 
 // parseDatabaseURL parses a given DB URL.
 //
 //	Branch dbName is optional.
-//	Format: https://{workspace_id}.{region}.xata.sh/db/{db_name}:{branch_name}
-func parseDatabaseURL(rawURL string) (databaseConfig, error) {
+//	Format: https://{workspace_id}.{region}.{domain}/db/{db_name}:{branch_name}
+//
+// The scheme and the port are kept. Only the hosts of the data plane domain, the default or
+// the one set with WithDataPlaneDomain or `XATA_DATA_PLANE_DOMAIN`, give the workspace ID and
+// the region. Any other host, e.g. http://localhost:8080/db/{db_name} or a proxy with a path
+// prefix, is used as is up to /db for every request, and the workspace ID and the region are
+// left empty.
+func parseDatabaseURL(rawURL string, dataPlaneDomain string) (databaseConfig, error) {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
 		return databaseConfig{}, err
 	}
-
-	host := strings.Split(parsedURL.Host, ".")
-	if len(host) != 4 {
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
 		return databaseConfig{}, fmt.Errorf("invalid databaseConfig URL: %s, expected format %s", rawURL, dbURLFormat)
 	}
 
-	path := strings.Split(parsedURL.Path, "/")
-	if len(path) != 3 {
+	// The path may have a prefix, e.g. for a proxy, but has to end with /db/{db_name}.
+	path := strings.Split(strings.TrimSuffix(parsedURL.Path, "/"), "/")
+	if len(path) < 3 || path[len(path)-2] != "db" || path[len(path)-1] == "" {
 		return databaseConfig{}, fmt.Errorf("invalid databaseConfig URL: %s, expected format %s", rawURL, dbURLFormat)
 	}
 
 	db := databaseConfig{
-		workspaceID:     host[0],
-		region:          host[1],
-		domainWorkspace: fmt.Sprintf("%s.%s", host[2], host[3]),
-		dbName:          path[2],
+		dbName:          path[len(path)-1],
+		domainWorkspace: dataPlaneDomain,
+		scheme:          parsedURL.Scheme,
+		port:            parsedURL.Port(),
+	}
+
+	hostname := parsedURL.Hostname()
+	if subdomain, found := strings.CutSuffix(hostname, "."+dataPlaneDomain); found {
+		host := strings.Split(subdomain, ".")
+		if len(host) != 2 || host[0] == "" || host[1] == "" {
+			return databaseConfig{}, fmt.Errorf("invalid databaseConfig URL: %s, expected format %s", rawURL, dbURLFormat)
+		}
+		db.workspaceID = host[0]
+		db.region = host[1]
+	} else {
+		custom := *parsedURL
+		custom.Path = strings.Join(path[:len(path)-2], "/")
+		custom.RawPath = ""
+		custom.RawQuery = ""
+		custom.Fragment = ""
+		db.customURL = custom.String()
 	}
 
 	if strings.Contains(db.dbName, ":") {
@@ -138,7 +189,7 @@ func parseDatabaseURL(rawURL string) (databaseConfig, error) {
 	}
 
 	if db.branchName == "" {
-		db.branchName = getBranchName(nil)
+		db.branchName = getBranchName(nil, defaultBranchName)
 	}
 
 	return db, err
@@ -188,21 +239,22 @@ func getEnvVar(name string, defaultValue string) string {
 	return defaultValue
 }
 
-// getBranchName retrieves the branch name. If not found, falls back to defaultBranchName.
-func getBranchName(opts *ClientOptions) string {
+// getBranchName retrieves the branch name from opts, and if empty from the `XATA_BRANCH`
+// environment variable, with a fallback to fallback.
+func getBranchName(opts *ClientOptions, fallback string) string {
 	if opts != nil && opts.Branch != "" {
 		return opts.Branch
 	}
-	return getEnvVar(EnvXataBranch, defaultBranchName)
+	return getEnvVar(EnvXataBranch, fallback)
 }
 
-// getRegion gets the region if the corresponding env var `XATA_REGION` is set otherwise return
-// defaultRegion.
-func getRegion(opts *ClientOptions) string {
+// getRegion gets the region from opts, and if empty from the `XATA_REGION` environment
+// variable, with a fallback to fallback.
+func getRegion(opts *ClientOptions, fallback string) string {
 	if opts != nil && opts.Region != "" {
 		return opts.Region
 	}
-	return getEnvVar(EnvXataRegion, defaultRegion)
+	return getEnvVar(EnvXataRegion, fallback)
 }
 
 // getWorkspaceID gets the workspace id from opts and if empty, gets it from the `XATA_WORKSPACE_ID`
@@ -214,12 +266,27 @@ func getWorkspaceID(opts *ClientOptions) string {
 	return getEnvVar(EnvXataWorkspaceID, "")
 }
 
+// getDataPlaneDomain gets the domain of the workspace API from opts, and if empty gets it from the
+// `XATA_DATA_PLANE_DOMAIN` environment variable, with a fallback to defaultDataPlaneDomain.
+func getDataPlaneDomain(opts *ClientOptions) string {
+	if opts != nil && opts.DataPlaneDomain != "" {
+		return opts.DataPlaneDomain
+	}
+	return getEnvVar(EnvXataDataPlaneDomain, defaultDataPlaneDomain)
+}
+
 // loadDatabaseConfig will return config with defaults if the error is not nil.
 func loadDatabaseConfig(cliOpts *ClientOptions) (databaseConfig, error) {
-	defaultDBConfig := databaseConfig{
+	var opts ClientOptions
+	if cliOpts != nil {
+		opts = *cliOpts
+	}
+
+	domain := getDataPlaneDomain(cliOpts)
+	dbCfg := databaseConfig{
 		region:          defaultRegion,
 		branchName:      defaultBranchName,
-		domainWorkspace: defaultDataPlaneDomain,
+		domainWorkspace: domain,
 	}
 
 	// Config can come from three places with differing priorities. The order from highest to lowest
@@ -227,28 +294,57 @@ func loadDatabaseConfig(cliOpts *ClientOptions) (databaseConfig, error) {
 	// 1. Code via ClientOptions
 	// 2. Environment variables
 	// 3. Config files
+	// Each value is resolved on its own, e.g. the region can be overridden while the database
+	// comes from the config file.
 
-	wsID := getWorkspaceID(cliOpts)
-	if wsID != "" {
-		db := databaseConfig{
-			workspaceID:     wsID,
-			region:          getRegion(cliOpts),
-			branchName:      getBranchName(cliOpts),
-			domainWorkspace: defaultDataPlaneDomain,
+	cfg, err := loadConfig(configFileName)
+	if err == nil {
+		var parsed databaseConfig
+		parsed, err = parseDatabaseURL(cfg.DatabaseURL, domain)
+		if err == nil {
+			dbCfg = parsed
+			if dbCfg.region == "" {
+				dbCfg.region = defaultRegion
+			}
 		}
-		return db, nil
 	}
 
-	// Config not found in code or environment variables, fall back to config files
-	cfg, err := loadConfig(configFileName)
-	if err != nil {
-		return defaultDBConfig, err
+	// A workspace ID or a region set in code routes to {workspace_id}.{region}.{domain}, even when
+	// the config file points at a custom URL.
+	if dbCfg.customURL != "" && (opts.WorkspaceID != "" || opts.Region != "") {
+		dbCfg.customURL = ""
+		dbCfg.scheme = ""
+		dbCfg.port = ""
 	}
 
-	dbCfg, err := parseDatabaseURL(cfg.DatabaseURL)
-	if err != nil {
-		return defaultDBConfig, err
+	if wsID := getWorkspaceID(cliOpts); wsID != "" {
+		dbCfg.workspaceID = wsID
+	}
+	if region := getRegion(cliOpts, dbCfg.region); region != "" {
+		dbCfg.region = region
+	}
+	if branch := getBranchName(cliOpts, dbCfg.branchName); branch != "" {
+		dbCfg.branchName = branch
+	}
+
+	if dbCfg.workspaceID == "" && dbCfg.customURL == "" {
+		if err == nil {
+			err = fmt.Errorf("workspace ID cannot be empty")
+		}
+		return dbCfg, err
 	}
 
 	return dbCfg, nil
 }
+
+// resolveWorkspaceID returns the workspace ID of a request, or the default workspace ID of the
+// client when the request doesn't set one.
+func resolveWorkspaceID(workspaceID *string, defaultWorkspaceID string) (string, error) {
+	if workspaceID != nil && *workspaceID != "" {
+		return *workspaceID, nil
+	}
+	if defaultWorkspaceID == "" {
+		return "", fmt.Errorf("workspace ID cannot be empty")
+	}
+	return defaultWorkspaceID, nil
+}
@@ -5,6 +5,7 @@ package xata
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,7 +30,7 @@ func TestClientOptions_getAPIKey(t *testing.T) {
 func Test_getBranchName(t *testing.T) {
 	// default state
 	t.Run("should be default branch name", func(t *testing.T) {
-		gotBranchName := getBranchName(nil)
+		gotBranchName := getBranchName(nil, defaultBranchName)
 		assert.Equal(t, gotBranchName, defaultBranchName)
 	})
 
@@ -38,14 +39,14 @@ func Test_getBranchName(t *testing.T) {
 
 	// from env var
 	t.Run("should be branch name from env var", func(t *testing.T) {
-		gotBranchName := getBranchName(nil)
+		gotBranchName := getBranchName(nil, defaultBranchName)
 		assert.Equal(t, setBranchName, gotBranchName)
 	})
 
 	// from ClientOptions
 	t.Run("from ClientOptions", func(t *testing.T) {
 		want := "branch-from-opts"
-		got := getBranchName(&ClientOptions{Branch: want})
+		got := getBranchName(&ClientOptions{Branch: want}, defaultBranchName)
 		assert.Equal(t, want, got)
 	})
 }
@@ -53,7 +54,7 @@ func Test_getBranchName(t *testing.T) {
 func Test_getRegion(t *testing.T) {
 	// default state
 	t.Run("should be default region", func(t *testing.T) {
-		gotRegion := getRegion(nil)
+		gotRegion := getRegion(nil, defaultRegion)
 		assert.Equal(t, gotRegion, defaultRegion)
 	})
 
@@ -65,13 +66,13 @@ func Test_getRegion(t *testing.T) {
 
 	// from env var
 	t.Run("should be region from the env var", func(t *testing.T) {
-		gotRegion := getRegion(nil)
+		gotRegion := getRegion(nil, defaultRegion)
 		assert.Equal(t, setRegion, gotRegion)
 	})
 
 	t.Run("should be region from ClientOptions", func(t *testing.T) {
 		wantRegion := "region-options"
-		gotRegion := getRegion(&ClientOptions{Region: wantRegion})
+		gotRegion := getRegion(&ClientOptions{Region: wantRegion}, defaultRegion)
 		assert.Equal(t, wantRegion, gotRegion)
 	})
 
@@ -94,6 +95,7 @@ func Test_parseDatabaseURL(t *testing.T) {
 				domainWorkspace: "xata.sh",
 				dbName:          "test-db",
 				branchName:      "main",
+				scheme:          "https",
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return err == nil
@@ -108,11 +110,64 @@ func Test_parseDatabaseURL(t *testing.T) {
 				domainWorkspace: "xata.sh",
 				dbName:          "test-db",
 				branchName:      "feature-branch",
+				scheme:          "https",
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return err == nil
 			},
 		},
+		{
+			name:   "should keep the scheme and the port",
+			rawURL: "http://my-workspace-id.us-east-1.xata.sh:8080/db/test-db:feature-branch",
+			want: databaseConfig{
+				workspaceID:     "my-workspace-id",
+				region:          "us-east-1",
+				domainWorkspace: "xata.sh",
+				dbName:          "test-db",
+				branchName:      "feature-branch",
+				scheme:          "http",
+				port:            "8080",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return err == nil
+			},
+		},
+		{
+			name:   "should use a host outside of the data plane domain as is",
+			rawURL: "http://localhost:8080/db/app:main",
+			want: databaseConfig{
+				domainWorkspace: "xata.sh",
+				dbName:          "app",
+				branchName:      "main",
+				scheme:          "http",
+				port:            "8080",
+				customURL:       "http://localhost:8080",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return err == nil
+			},
+		},
+		{
+			name:   "should keep the path prefix of a proxy",
+			rawURL: "https://proxy.example.com/xata/db/app:dev",
+			want: databaseConfig{
+				domainWorkspace: "xata.sh",
+				dbName:          "app",
+				branchName:      "dev",
+				scheme:          "https",
+				customURL:       "https://proxy.example.com/xata",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return err == nil
+			},
+		},
+		{
+			name:   "should fail without a scheme",
+			rawURL: "my-workspace-id.us-east-1.xata.sh/db/test-db",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "invalid databaseConfig URL")
+			},
+		},
 		{
 			name:   "should fail when host is not in expected format",
 			rawURL: "https://unexpected-field.my-workspace-id.us-east-1.xata.sh/db/test-db:feature-branch",
@@ -137,7 +192,7 @@ func Test_parseDatabaseURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseDatabaseURL(tt.rawURL)
+			got, err := parseDatabaseURL(tt.rawURL, defaultDataPlaneDomain)
 			if !tt.wantErr(t, err, fmt.Sprintf("parseDatabaseURL(%v)", tt.rawURL)) {
 				return
 			}
@@ -146,6 +201,35 @@ func Test_parseDatabaseURL(t *testing.T) {
 	}
 }
 
+func Test_parseDatabaseURL_dataPlaneDomain(t *testing.T) {
+	got, err := parseDatabaseURL("https://my-workspace-id.eu-west-1.staging.example.dev/db/test-db", "staging.example.dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-workspace-id", got.workspaceID)
+	assert.Equal(t, "eu-west-1", got.region)
+	assert.Equal(t, "staging.example.dev", got.domainWorkspace)
+	assert.Empty(t, got.customURL)
+	assert.Equal(t, "https://my-workspace-id.us-east-1.staging.example.dev", got.dataPlaneURL("us-east-1"))
+
+	// Outside of the data plane domain, the hosts are custom URLs whatever their shape.
+	for rawURL, want := range map[string]string{
+		"https://my-workspace-id.eu-west-1.staging.example.dev/db/test-db": "https://my-workspace-id.eu-west-1.staging.example.dev",
+		"https://my-workspace-id.us-east-1.xata.tech/db/app":               "https://my-workspace-id.us-east-1.xata.tech",
+		"https://xata.proxy.corp.example.com/db/app":                       "https://xata.proxy.corp.example.com",
+		"https://a.b.corp.example.com/xata/db/app":                         "https://a.b.corp.example.com/xata",
+	} {
+		got, err = parseDatabaseURL(rawURL, defaultDataPlaneDomain)
+		assert.NoError(t, err)
+		assert.Empty(t, got.workspaceID, rawURL)
+		assert.Empty(t, got.region, rawURL)
+		assert.Equal(t, want, got.dataPlaneURL("us-east-1"))
+	}
+
+	got, err = parseDatabaseURL("http://10.0.0.1:8080/db/app", defaultDataPlaneDomain)
+	assert.NoError(t, err)
+	assert.Empty(t, got.workspaceID)
+	assert.Equal(t, "http://10.0.0.1:8080", got.dataPlaneURL("us-east-1"))
+}
+
 func Test_loadConfig(t *testing.T) {
 	// from .xatarc
 	t.Run("should read database URL", func(t *testing.T) {
@@ -239,3 +323,79 @@ func setEnvForTests(t *testing.T, key, value string) {
 		assert.NoError(t, os.Unsetenv(key))
 	})
 }
+
+// The tests of this package change the working directory and the environment, so none of them
+// may use t.Parallel.
+func Test_loadDatabaseConfig_with_xatarc(t *testing.T) {
+	writeXatarc := func(t *testing.T, databaseURL string) {
+		t.Helper()
+
+		wd, err := os.Getwd()
+		assert.NoError(t, err)
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, configFileName), []byte(fmt.Sprintf(`{"databaseURL": %q}`, databaseURL)), 0o600))
+		assert.NoError(t, os.Chdir(dir))
+		t.Cleanup(func() { assert.NoError(t, os.Chdir(wd)) })
+	}
+
+	t.Run("should override the values of the config file one by one", func(t *testing.T) {
+		writeXatarc(t, "https://ws-from-file.eu-west-1.xata.sh/db/app:dev")
+
+		dbCfg, err := loadDatabaseConfig(&ClientOptions{Region: "us-west-2"})
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-from-file", dbCfg.workspaceID)
+		assert.Equal(t, "us-west-2", dbCfg.region)
+		assert.Equal(t, "app", dbCfg.dbName)
+		assert.Equal(t, "dev", dbCfg.branchName)
+
+		dbCfg, err = loadDatabaseConfig(&ClientOptions{WorkspaceID: "ws-from-code"})
+		assert.NoError(t, err)
+		assert.Equal(t, "ws-from-code", dbCfg.workspaceID)
+		assert.Equal(t, "eu-west-1", dbCfg.region)
+		assert.Equal(t, "app", dbCfg.dbName)
+	})
+
+	t.Run("should point the clients at a local emulator", func(t *testing.T) {
+		writeXatarc(t, "http://localhost:8080/db/app:main")
+
+		cliOpts, dbCfg, err := consolidateClientOptionsForWorkspace(WithAPIKey("test-key"))
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost:8080", cliOpts.BaseURL)
+		assert.Equal(t, "app", dbCfg.dbName)
+	})
+
+	t.Run("should route to the workspace when it is set in code", func(t *testing.T) {
+		writeXatarc(t, "http://localhost:8080/db/app:main")
+
+		cliOpts, dbCfg, err := consolidateClientOptionsForWorkspace(
+			WithAPIKey("test-key"),
+			WithWorkspaceID("ws-id"),
+			WithRegion("eu-west-1"),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://ws-id.eu-west-1.xata.sh", cliOpts.BaseURL)
+		assert.Equal(t, "app", dbCfg.dbName)
+
+		_, err = loadDatabaseConfig(&ClientOptions{Region: "eu-west-1"})
+		assert.ErrorContains(t, err, "workspace ID cannot be empty")
+	})
+}
+
+func Test_consolidateClientOptionsForWorkspace_dataPlaneDomain(t *testing.T) {
+	cliOpts, _, err := consolidateClientOptionsForWorkspace(
+		WithAPIKey("test-key"),
+		WithWorkspaceID("ws-id"),
+		WithRegion("eu-central-1"),
+		WithDataPlaneDomain("staging-xata.dev"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ws-id.eu-central-1.staging-xata.dev", cliOpts.BaseURL)
+}
+
+func Test_getDataPlaneDomain(t *testing.T) {
+	assert.Equal(t, defaultDataPlaneDomain, getDataPlaneDomain(nil))
+
+	setEnvForTests(t, EnvXataDataPlaneDomain, "staging-xata.dev")
+	assert.Equal(t, "staging-xata.dev", getDataPlaneDomain(nil))
+	assert.Equal(t, "private.example.com", getDataPlaneDomain(&ClientOptions{DataPlaneDomain: "private.example.com"}))
+}
@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MemberChangeAction is the kind of change a MemberChange applies to a workspace.
+type MemberChangeAction string
+
+const (
+	MemberChangeUpdateRole   MemberChangeAction = "update-role"
+	MemberChangeRemove       MemberChangeAction = "remove"
+	MemberChangeInvite       MemberChangeAction = "invite"
+	MemberChangeUpdateInvite MemberChangeAction = "update-invite"
+	MemberChangeCancelInvite MemberChangeAction = "cancel-invite"
+)
+
+// MemberChange is a single step of a member reconciliation plan.
+type MemberChange struct {
+	Action MemberChangeAction
+	Email  string
+	// UserID is set for changes to existing members.
+	UserID string
+	// InviteID is set for changes to pending invites.
+	InviteID string
+	// Role is the role after the change, unset for removals and cancellations.
+	Role Role
+	// PreviousRole is the role before the change, unset for new invites.
+	PreviousRole Role
+}
+
+func (c MemberChange) String() string {
+	switch c.Action {
+	case MemberChangeUpdateRole, MemberChangeUpdateInvite:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Action, c.Email, c.PreviousRole, c.Role)
+	case MemberChangeInvite:
+		return fmt.Sprintf("%s %s: %s", c.Action, c.Email, c.Role)
+	default:
+		return fmt.Sprintf("%s %s", c.Action, c.Email)
+	}
+}
+
+type ReconcileMembersRequest struct {
+	// WorkspaceID defaults to the workspace of the clients.
+	WorkspaceID *string
+	// Desired maps the email of every expected member to its role. It cannot be empty.
+	Desired map[string]Role
+	// AllowRemovals removes the members and cancels the invites not listed in Desired.
+	// Without it, they are left as is.
+	AllowRemovals bool
+	// DryRun only computes the plan.
+	DryRun bool
+}
+
+// ReconcileMembers brings the members and pending invites of a workspace in line with the
+// desired member to role map, using the minimal set of role changes, removals and invites.
+// Emails are compared case-insensitively. It returns the plan, and with an error the
+// changes that were applied before the failure.
+// Role changes and invites are applied before removals, so that a workspace doesn't lose its
+// last owner halfway through. A plan removing the calling user, or leaving the workspace
+// without owners, is rejected.
+func ReconcileMembers(ctx context.Context, workspaces WorkspacesClient, invites InvitesClient, users UsersClient, request ReconcileMembersRequest) ([]MemberChange, error) {
+	if len(request.Desired) == 0 {
+		return nil, fmt.Errorf("desired members cannot be empty")
+	}
+
+	desired := make(map[string]Role, len(request.Desired))
+	for email, role := range request.Desired {
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("invalid email: %q", email)
+		}
+		if err := role.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", email, err)
+		}
+
+		key := strings.ToLower(email)
+		if _, ok := desired[key]; ok {
+			return nil, fmt.Errorf("duplicate email: %q", email)
+		}
+		desired[key] = role
+	}
+
+	current, err := workspaces.ListMembers(ctx, ListWorkspaceMembersRequest{WorkspaceID: request.WorkspaceID})
+	if err != nil {
+		return nil, err
+	}
+
+	var plan, removals []MemberChange
+	seen := make(map[string]bool, len(desired))
+	owners := 0
+
+	for _, m := range current.Members {
+		key := strings.ToLower(m.Email)
+		seen[key] = true
+
+		role, ok := desired[key]
+		switch {
+		case !ok && request.AllowRemovals:
+			removals = append(removals, MemberChange{Action: MemberChangeRemove, Email: m.Email, UserID: m.UserId, PreviousRole: Role(m.Role)})
+			continue
+		case !ok:
+			role = Role(m.Role)
+		case role != Role(m.Role):
+			plan = append(plan, MemberChange{Action: MemberChangeUpdateRole, Email: m.Email, UserID: m.UserId, Role: role, PreviousRole: Role(m.Role)})
+		}
+		if role == RoleOwner {
+			owners++
+		}
+	}
+	if owners == 0 {
+		return nil, fmt.Errorf("the workspace would be left without owners")
+	}
+
+	for _, i := range current.Invites {
+		key := strings.ToLower(i.Email)
+		role, ok := desired[key]
+		switch {
+		case seen[key] || (!ok && request.AllowRemovals):
+			removals = append(removals, MemberChange{Action: MemberChangeCancelInvite, Email: i.Email, InviteID: i.InviteId, PreviousRole: Role(i.Role)})
+		case ok && role != Role(i.Role):
+			plan = append(plan, MemberChange{Action: MemberChangeUpdateInvite, Email: i.Email, InviteID: i.InviteId, Role: role, PreviousRole: Role(i.Role)})
+		}
+		seen[key] = true
+	}
+
+	var missing []string
+	for email := range request.Desired {
+		if !seen[strings.ToLower(email)] {
+			missing = append(missing, email)
+		}
+	}
+	sort.Strings(missing)
+	for _, email := range missing {
+		plan = append(plan, MemberChange{Action: MemberChangeInvite, Email: email, Role: desired[strings.ToLower(email)]})
+	}
+
+	if err := checkCallerKept(ctx, users, removals); err != nil {
+		return nil, err
+	}
+
+	plan = append(plan, removals...)
+	if request.DryRun {
+		return plan, nil
+	}
+
+	for n, change := range plan {
+		if err := applyMemberChange(ctx, workspaces, invites, request.WorkspaceID, change); err != nil {
+			return plan[:n], fmt.Errorf("unable to %s: %w", change, err)
+		}
+	}
+
+	return plan, nil
+}
+
+// checkCallerKept fails if the removals include the calling user. The user is only looked up
+// when members are removed.
+func checkCallerKept(ctx context.Context, users UsersClient, removals []MemberChange) error {
+	var removed []MemberChange
+	for _, change := range removals {
+		if change.Action == MemberChangeRemove {
+			removed = append(removed, change)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	caller, err := users.Get(ctx)
+	if err != nil {
+		return err
+	}
+	for _, change := range removed {
+		if change.UserID == caller.Id {
+			return fmt.Errorf("cannot remove the calling user %s", change.Email)
+		}
+	}
+	return nil
+}
+
+func applyMemberChange(ctx context.Context, workspaces WorkspacesClient, invites InvitesClient, workspaceID *string, change MemberChange) error {
+	switch change.Action {
+	case MemberChangeUpdateRole:
+		return workspaces.UpdateMemberRole(ctx, UpdateWorkspaceMemberRoleRequest{WorkspaceID: workspaceID, UserID: change.UserID, Role: change.Role})
+	case MemberChangeRemove:
+		return workspaces.RemoveMember(ctx, RemoveWorkspaceMemberRequest{WorkspaceID: workspaceID, UserID: change.UserID})
+	case MemberChangeInvite:
+		_, err := invites.Invite(ctx, InviteWorkspaceMemberRequest{WorkspaceID: workspaceID, Email: change.Email, Role: change.Role})
+		return err
+	case MemberChangeUpdateInvite:
+		_, err := invites.Update(ctx, UpdateWorkspaceMemberInviteRequest{WorkspaceID: workspaceID, InviteID: change.InviteID, Role: change.Role})
+		return err
+	case MemberChangeCancelInvite:
+		return invites.Cancel(ctx, WorkspaceMemberInviteRequest{WorkspaceID: workspaceID, InviteID: change.InviteID})
+	default:
+		return fmt.Errorf("unknown member change: %s", change.Action)
+	}
+}
@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+// membersService serves the member listing and the calling user, and records the writes sent
+// to the member and invite endpoints.
+type membersService struct {
+	t       *testing.T
+	members map[string]any
+	caller  string
+	failOn  string
+
+	mu     sync.Mutex
+	writes []string
+}
+
+func (m *membersService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.Method == http.MethodGet && r.URL.Path == "/user" {
+		assert.NoError(m.t, json.NewEncoder(w).Encode(map[string]any{"id": m.caller, "email": m.caller + "@example.com"}))
+		return
+	}
+	if r.Method == http.MethodGet {
+		assert.Equal(m.t, "/workspaces/ws-id/members", r.URL.Path)
+		assert.NoError(m.t, json.NewEncoder(w).Encode(m.members))
+		return
+	}
+
+	write := r.Method + " " + r.URL.Path
+	m.writes = append(m.writes, write)
+	if write == m.failOn {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "failed"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodDelete, http.MethodPut:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		assert.NoError(m.t, json.NewEncoder(w).Encode(map[string]any{"inviteId": "new-invite"}))
+	}
+}
+
+func Test_ReconcileMembers(t *testing.T) {
+	members := map[string]any{
+		"members": []map[string]any{
+			{"userId": "u-owner", "email": "owner@example.com", "role": "owner"},
+			{"userId": "u-demote", "email": "Demote@example.com", "role": "owner"},
+			{"userId": "u-gone", "email": "gone@example.com", "role": "maintainer"},
+		},
+		"invites": []map[string]any{
+			{"inviteId": "i-keep", "email": "pending@example.com", "role": "maintainer"},
+			{"inviteId": "i-promote", "email": "promote@example.com", "role": "maintainer"},
+			{"inviteId": "i-stale", "email": "stale@example.com", "role": "maintainer"},
+		},
+	}
+	desired := map[string]xata.Role{
+		"owner@example.com":   xata.RoleOwner,
+		"demote@example.com":  xata.RoleMaintainer,
+		"pending@example.com": xata.RoleMaintainer,
+		"promote@example.com": xata.RoleOwner,
+		"new@example.com":     xata.RoleMaintainer,
+	}
+
+	reconcile := func(t *testing.T, svc *membersService, request xata.ReconcileMembersRequest) ([]xata.MemberChange, error) {
+		testSrv := httptest.NewServer(svc)
+		t.Cleanup(testSrv.Close)
+
+		opts := []xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id")}
+		workspaces, err := xata.NewWorkspacesClient(opts...)
+		assert.NoError(t, err)
+		invites, err := xata.NewInvitesClient(opts...)
+		assert.NoError(t, err)
+		users, err := xata.NewUsersClient(opts...)
+		assert.NoError(t, err)
+		return xata.ReconcileMembers(context.TODO(), workspaces, invites, users, request)
+	}
+
+	wantPlan := []xata.MemberChange{
+		{Action: xata.MemberChangeUpdateRole, Email: "Demote@example.com", UserID: "u-demote", Role: xata.RoleMaintainer, PreviousRole: xata.RoleOwner},
+		{Action: xata.MemberChangeUpdateInvite, Email: "promote@example.com", InviteID: "i-promote", Role: xata.RoleOwner, PreviousRole: xata.RoleMaintainer},
+		{Action: xata.MemberChangeInvite, Email: "new@example.com", Role: xata.RoleMaintainer},
+		{Action: xata.MemberChangeRemove, Email: "gone@example.com", UserID: "u-gone", PreviousRole: xata.RoleMaintainer},
+		{Action: xata.MemberChangeCancelInvite, Email: "stale@example.com", InviteID: "i-stale", PreviousRole: xata.RoleMaintainer},
+	}
+
+	t.Run("should only plan on dry run", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner"}
+		got, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: desired, AllowRemovals: true, DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, wantPlan, got)
+		assert.Empty(t, svc.writes)
+		assert.Equal(t, "update-role Demote@example.com: owner -> maintainer", got[0].String())
+	})
+
+	t.Run("should apply the minimal changes", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner"}
+		got, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: desired, AllowRemovals: true})
+		assert.NoError(t, err)
+		assert.Equal(t, wantPlan, got)
+		assert.Equal(t, []string{
+			"PUT /workspaces/ws-id/members/u-demote",
+			"PATCH /workspaces/ws-id/invites/i-promote",
+			"POST /workspaces/ws-id/invites",
+			"DELETE /workspaces/ws-id/members/u-gone",
+			"DELETE /workspaces/ws-id/invites/i-stale",
+		}, svc.writes)
+	})
+
+	t.Run("should keep the members not listed without allow removals", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner"}
+		got, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: desired, DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, wantPlan[:3], got)
+	})
+
+	t.Run("should return the applied changes on failure", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner", failOn: "POST /workspaces/ws-id/invites"}
+		got, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: desired, AllowRemovals: true})
+		assert.Error(t, err)
+		assert.Equal(t, wantPlan[:2], got)
+		assert.Len(t, svc.writes, 3)
+	})
+
+	t.Run("should not remove the calling user", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-gone"}
+		_, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: desired, AllowRemovals: true})
+		assert.Error(t, err)
+		assert.Empty(t, svc.writes)
+	})
+
+	t.Run("should not leave the workspace without owners", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner"}
+		_, err := reconcile(t, svc, xata.ReconcileMembersRequest{
+			Desired: map[string]xata.Role{
+				"owner@example.com":  xata.RoleMaintainer,
+				"demote@example.com": xata.RoleMaintainer,
+				"gone@example.com":   xata.RoleMaintainer,
+			},
+		})
+		assert.Error(t, err)
+
+		_, err = reconcile(t, svc, xata.ReconcileMembersRequest{
+			Desired:       map[string]xata.Role{"gone@example.com": xata.RoleMaintainer},
+			AllowRemovals: true,
+		})
+		assert.Error(t, err)
+		assert.Empty(t, svc.writes)
+	})
+
+	t.Run("should validate the desired members", func(t *testing.T) {
+		svc := &membersService{t: t, members: members, caller: "u-owner"}
+		for _, invalid := range []map[string]xata.Role{
+			nil,
+			{},
+			{"jane": xata.RoleOwner},
+			{"jane@example.com": 0},
+			{"jane@example.com": xata.RoleOwner, "JANE@example.com": xata.RoleOwner},
+		} {
+			_, err := reconcile(t, svc, xata.ReconcileMembersRequest{Desired: invalid, AllowRemovals: true})
+			assert.Error(t, err)
+		}
+		assert.Empty(t, svc.writes)
+	})
+}
@@ -4,6 +4,7 @@ package xata
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	xatagencore "github.com/xataio/xata-go/xata/internal/fern-core/generated/go"
@@ -17,6 +18,24 @@ type UpdateWorkspaceRequest struct {
 	WorkspaceID *string
 }
 
+type ListWorkspaceMembersRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+}
+
+type UpdateWorkspaceMemberRoleRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	UserID      string
+	Role        Role
+}
+
+type RemoveWorkspaceMemberRequest struct {
+	// WorkspaceID defaults to the workspace of the client.
+	WorkspaceID *string
+	UserID      string
+}
+
 type WorkspacesClient interface {
 	List(ctx context.Context) (*xatagencore.GetWorkspacesListResponse, error)
 	Create(ctx context.Context, request *WorkspaceMeta) (*xatagencore.Workspace, error)
@@ -24,6 +43,9 @@ type WorkspacesClient interface {
 	Get(ctx context.Context) (*xatagencore.Workspace, error)
 	GetWithWorkspaceID(ctx context.Context, workspaceID string) (*xatagencore.Workspace, error)
 	Update(ctx context.Context, request UpdateWorkspaceRequest) (*xatagencore.Workspace, error)
+	ListMembers(ctx context.Context, request ListWorkspaceMembersRequest) (*xatagencore.WorkspaceMembers, error)
+	UpdateMemberRole(ctx context.Context, request UpdateWorkspaceMemberRoleRequest) error
+	RemoveMember(ctx context.Context, request RemoveWorkspaceMemberRequest) error
 }
 
 type workspaceCli struct {
@@ -72,6 +94,50 @@ func (w workspaceCli) Update(ctx context.Context, request UpdateWorkspaceRequest
 	return w.generated.UpdateWorkspace(ctx, workspaceID, (*xatagencore.WorkspaceMeta)(request.Payload))
 }
 
+// ListMembers retrieves the members of the workspace and its pending invites.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/members#get-the-list-members-of-a-workspace
+func (w workspaceCli) ListMembers(ctx context.Context, request ListWorkspaceMembersRequest) (*xatagencore.WorkspaceMembers, error) {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, w.workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.generated.GetWorkspaceMembersList(ctx, workspaceID)
+}
+
+// UpdateMemberRole changes the role of a member of the workspace.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/members/user_id#update-workspace-member-role
+func (w workspaceCli) UpdateMemberRole(ctx context.Context, request UpdateWorkspaceMemberRoleRequest) error {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, w.workspaceID)
+	if err != nil {
+		return err
+	}
+	if request.UserID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+	if err := request.Role.validate(); err != nil {
+		return err
+	}
+
+	return w.generated.UpdateWorkspaceMemberRole(ctx, workspaceID, request.UserID, &xatagencore.UpdateWorkspaceMemberRoleRequest{
+		Role: xatagencore.Role(request.Role),
+	})
+}
+
+// RemoveMember removes a member from the workspace.
+// https://xata.io/docs/api-reference/workspaces/workspace_id/members/user_id#remove-workspace-member
+func (w workspaceCli) RemoveMember(ctx context.Context, request RemoveWorkspaceMemberRequest) error {
+	workspaceID, err := resolveWorkspaceID(request.WorkspaceID, w.workspaceID)
+	if err != nil {
+		return err
+	}
+	if request.UserID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	return w.generated.RemoveWorkspaceMember(ctx, workspaceID, request.UserID)
+}
+
 // NewWorkspacesClient constructs a client for interacting with workspaces.
 func NewWorkspacesClient(opts ...ClientOption) (WorkspacesClient, error) {
 	cliOpts, err := consolidateClientOptionsForCore(opts...)
@@ -402,3 +402,96 @@ func Test_workspacesClient_Update(t *testing.T) {
 		})
 	}
 }
+
+func Test_workspacesClient_ListMembers(t *testing.T) {
+	assert := assert.New(t)
+
+	type tc struct {
+		name       string
+		want       *xatagen.WorkspaceMembers
+		statusCode int
+		apiErr     *xatagencore.APIError
+	}
+
+	tests := []tc{
+		{
+			name: "should list the workspace members",
+			want: &xatagen.WorkspaceMembers{
+				Members: []*xatagen.WorkspaceMember{
+					{UserId: "user-id", Fullname: "Jane Doe", Email: "jane@example.com", Role: xatagen.RoleOwner},
+				},
+				Invites: []*xatagen.WorkspaceInvite{
+					{InviteId: "invite-id", Email: "john@example.com", Role: xatagen.RoleMaintainer},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, eTC := range errTestCasesCore {
+		tests = append(tests, tc{
+			name:       eTC.name,
+			statusCode: eTC.statusCode,
+			apiErr:     eTC.apiErr,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testSrv := testService(t, http.MethodGet, "/workspaces/ws-id/members", tt.statusCode, tt.apiErr != nil, tt.want)
+
+			cli, err := xata.NewWorkspacesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+			assert.NoError(err)
+
+			got, err := cli.ListMembers(context.TODO(), xata.ListWorkspaceMembersRequest{})
+
+			if tt.apiErr != nil {
+				errAPI := tt.apiErr.Unwrap()
+				if errAPI == nil {
+					t.Fatal("expected error but got nil")
+				}
+				assert.ErrorAs(err, &errAPI)
+				assert.Equal(err.Error(), tt.apiErr.Error())
+				assert.Nil(got)
+			} else {
+				assert.NoError(err)
+				assert.Equal(tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_workspacesClient_UpdateMemberRole_RemoveMember(t *testing.T) {
+	t.Run("should update the role of a member", func(t *testing.T) {
+		testSrv := testService(t, http.MethodPut, "/workspaces/other-ws/members/user-id", http.StatusNoContent, false, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewWorkspacesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		assert.NoError(t, cli.UpdateMemberRole(context.TODO(), xata.UpdateWorkspaceMemberRoleRequest{
+			WorkspaceID: xata.String("other-ws"),
+			UserID:      "user-id",
+			Role:        xata.RoleMaintainer,
+		}))
+	})
+
+	t.Run("should remove a member", func(t *testing.T) {
+		testSrv := testService(t, http.MethodDelete, "/workspaces/ws-id/members/user-id", http.StatusNoContent, false, nil)
+		defer testSrv.Close()
+
+		cli, err := xata.NewWorkspacesClient(xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		assert.NoError(t, cli.RemoveMember(context.TODO(), xata.RemoveWorkspaceMemberRequest{UserID: "user-id"}))
+	})
+
+	t.Run("should validate the requests", func(t *testing.T) {
+		cli, err := xata.NewWorkspacesClient(xata.WithBaseURL("http://localhost"), xata.WithAPIKey("test-key"), xata.WithWorkspaceID("ws-id"))
+		assert.NoError(t, err)
+
+		assert.EqualError(t, cli.UpdateMemberRole(context.TODO(), xata.UpdateWorkspaceMemberRoleRequest{Role: xata.RoleOwner}), "user ID cannot be empty")
+		assert.EqualError(t, cli.UpdateMemberRole(context.TODO(), xata.UpdateWorkspaceMemberRoleRequest{UserID: "user-id"}), "invalid role: 0")
+		assert.EqualError(t, cli.RemoveMember(context.TODO(), xata.RemoveWorkspaceMemberRequest{}), "user ID cannot be empty")
+	})
+}
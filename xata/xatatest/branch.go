@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package xatatest provides helpers for tests running against a Xata database.
+//
+//	func TestUsers(t *testing.T) {
+//		branch := xatatest.EphemeralBranch(t, xatatest.Options{DatabaseName: "app"})
+//
+//		records, err := xata.NewRecordsClient(branch.ClientOptions...)
+//		...
+//	}
+//
+// Every test gets its own branch, forked from the schema of a base branch and deleted when
+// the test ends. The branches are labelled so that Sweep can remove the ones a killed test
+// run left behind.
+package xatatest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/xataio/xata-go/xata"
+)
+
+const (
+	// Label marks the branches created by EphemeralBranch in their metadata.
+	Label = "xatatest:ephemeral"
+
+	defaultPrefix        = "xatatest"
+	defaultBaseBranch    = "main"
+	defaultSweepMaxAge   = time.Hour
+	cleanupTimeout       = time.Minute
+	branchNameRandomSize = 12
+)
+
+// Options configure EphemeralBranch.
+type Options struct {
+	// DatabaseName is the database the branch is created in. It is required.
+	DatabaseName string
+	// BaseBranch is the branch the schema is forked from, main by default.
+	BaseBranch string
+	// Prefix starts the name of the branch, xatatest by default.
+	Prefix string
+	// ClientOptions configure the clients, e.g. xata.WithAPIKey or xata.WithBaseURL.
+	ClientOptions []xata.ClientOption
+	// Seed inserts the fixtures of the test once the branch is created.
+	Seed func(ctx context.Context, branch *Branch) error
+}
+
+// Branch is a branch created for a test.
+type Branch struct {
+	DatabaseName string
+	BranchName   string
+	// ClientOptions point the clients at the branch. The database name still has to be set in
+	// the requests.
+	ClientOptions []xata.ClientOption
+}
+
+// EphemeralBranch creates a uniquely named branch for the test, and deletes it when the test
+// and its subtests complete, including when they fail or panic.
+func EphemeralBranch(t testing.TB, opts Options) *Branch {
+	t.Helper()
+
+	if opts.DatabaseName == "" {
+		t.Fatal("xatatest: database name cannot be empty")
+	}
+
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = defaultBaseBranch
+	}
+
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+
+	branch := &Branch{
+		DatabaseName: opts.DatabaseName,
+		BranchName:   fmt.Sprintf("%s-%s", prefix, strings.ReplaceAll(uuid.NewString(), "-", "")[:branchNameRandomSize]),
+	}
+	branch.ClientOptions = append(append([]xata.ClientOption{}, opts.ClientOptions...), xata.WithBranch(branch.BranchName))
+
+	branchCli, err := xata.NewBranchClient(branch.ClientOptions...)
+	if err != nil {
+		t.Fatalf("xatatest: unable to construct the branch client: %v", err)
+	}
+
+	ctx := context.Background()
+	_, err = branchCli.Create(ctx, xata.CreateBranchRequest{
+		DatabaseName: xata.String(branch.DatabaseName),
+		BranchName:   branch.BranchName,
+		From:         xata.String(baseBranch),
+		Payload: &xata.CreateBranchRequestPayload{
+			CreateBranchRequestFrom: xata.String(baseBranch),
+			Metadata: &xata.BranchMetadataWS{
+				Stage:  xata.String("test"),
+				Labels: &[]string{Label},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("xatatest: unable to create branch %s from %s: %v", branch.BranchName, baseBranch, err)
+	}
+
+	// Registered before seeding, so that the branch is deleted when seeding fails.
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+
+		if _, err := branchCli.Delete(ctx, xata.BranchRequest{
+			DatabaseName: xata.String(branch.DatabaseName),
+			BranchName:   branch.BranchName,
+		}); err != nil {
+			t.Errorf("xatatest: unable to delete branch %s: %v", branch.BranchName, err)
+		}
+	})
+
+	if opts.Seed != nil {
+		if err := opts.Seed(ctx, branch); err != nil {
+			t.Fatalf("xatatest: unable to seed branch %s: %v", branch.BranchName, err)
+		}
+	}
+
+	return branch
+}
+
+// SweepOptions configure Sweep.
+type SweepOptions struct {
+	DatabaseName  string
+	ClientOptions []xata.ClientOption
+	// MaxAge is the age from which a labelled branch is considered orphaned, 1 hour by default.
+	// It must be longer than the slowest test using EphemeralBranch.
+	MaxAge time.Duration
+	// DryRun only returns the orphaned branches.
+	DryRun bool
+}
+
+// Sweep deletes the branches created by EphemeralBranch and older than the max age, left
+// behind by test runs that were killed before their cleanup. It returns the names of the
+// orphaned branches.
+func Sweep(ctx context.Context, opts SweepOptions) ([]string, error) {
+	if opts.DatabaseName == "" {
+		return nil, fmt.Errorf("database name cannot be empty")
+	}
+
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultSweepMaxAge
+	}
+
+	branchCli, err := xata.NewBranchClient(opts.ClientOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := branchCli.List(ctx, opts.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for _, b := range list.Branches {
+		if time.Since(b.CreatedAt) < maxAge {
+			continue
+		}
+
+		details, err := branchCli.GetDetails(ctx, xata.BranchRequest{DatabaseName: xata.String(opts.DatabaseName), BranchName: b.Name})
+		if err != nil {
+			return orphans, err
+		}
+		if !hasLabel((*xata.BranchMetadataWS)(details.Metadata), Label) {
+			continue
+		}
+
+		orphans = append(orphans, b.Name)
+		if opts.DryRun {
+			continue
+		}
+
+		if _, err := branchCli.Delete(ctx, xata.BranchRequest{DatabaseName: xata.String(opts.DatabaseName), BranchName: b.Name}); err != nil {
+			return orphans, fmt.Errorf("unable to delete branch %s: %w", b.Name, err)
+		}
+	}
+
+	return orphans, nil
+}
+
+func hasLabel(metadata *xata.BranchMetadataWS, label string) bool {
+	if metadata == nil || metadata.Labels == nil {
+		return false
+	}
+	for _, l := range *metadata.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
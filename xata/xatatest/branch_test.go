@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package xatatest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/xata-go/xata"
+	"github.com/xataio/xata-go/xata/xatatest"
+)
+
+type branch struct {
+	createdAt time.Time
+	labels    []string
+	from      string
+}
+
+// branchService keeps the branches of the database "db".
+type branchService struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	branches map[string]*branch
+}
+
+func (b *branchService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if r.URL.Path == "/dbs/db" {
+		var branches []map[string]any
+		for name, br := range b.branches {
+			branches = append(branches, map[string]any{"name": name, "createdAt": br.createdAt})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"databaseName": "db", "branches": branches})
+		return
+	}
+
+	name, found := strings.CutPrefix(r.URL.Path, "/db/db:")
+	if !found {
+		// Not t.Fatal, the handler doesn't run in the test goroutine.
+		b.t.Errorf("unexpected path: %s", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			From     string `json:"from"`
+			Metadata struct {
+				Labels []string `json:"labels"`
+			} `json:"metadata"`
+		}
+		assert.NoError(b.t, json.NewDecoder(r.Body).Decode(&body))
+		b.branches[name] = &branch{createdAt: time.Now(), labels: body.Metadata.Labels, from: body.From}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"databaseName": "db", "branchName": name})
+	case http.MethodGet:
+		br := b.branches[name]
+		_ = json.NewEncoder(w).Encode(map[string]any{"databaseName": "db", "branchName": name, "metadata": map[string]any{"labels": br.labels}})
+	case http.MethodDelete:
+		delete(b.branches, name)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "completed"})
+	default:
+		b.t.Errorf("unexpected method: %s", r.Method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestEphemeralBranch(t *testing.T) {
+	svc := &branchService{t: t, branches: map[string]*branch{"main": {}}}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	var created *xatatest.Branch
+	var seeded bool
+
+	t.Run("test using a branch", func(t *testing.T) {
+		created = xatatest.EphemeralBranch(t, xatatest.Options{
+			DatabaseName:  "db",
+			BaseBranch:    "main",
+			ClientOptions: []xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key")},
+			Seed: func(_ context.Context, branch *xatatest.Branch) error {
+				seeded = assert.Contains(t, svc.branches, branch.BranchName)
+				return nil
+			},
+		})
+
+		assert.True(t, strings.HasPrefix(created.BranchName, "xatatest-"))
+		assert.Equal(t, "main", svc.branches[created.BranchName].from)
+		assert.Equal(t, []string{xatatest.Label}, svc.branches[created.BranchName].labels)
+		assert.Len(t, created.ClientOptions, 3)
+	})
+
+	assert.True(t, seeded)
+	assert.NotContains(t, svc.branches, created.BranchName)
+	assert.Contains(t, svc.branches, "main")
+}
+
+func TestSweep(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+	svc := &branchService{t: t, branches: map[string]*branch{
+		"main":            {createdAt: old},
+		"xatatest-orphan": {createdAt: old, labels: []string{xatatest.Label}},
+		"xatatest-recent": {createdAt: time.Now(), labels: []string{xatatest.Label}},
+		"feature":         {createdAt: old, labels: []string{"preview"}},
+	}}
+	testSrv := httptest.NewServer(svc)
+	defer testSrv.Close()
+
+	opts := xatatest.SweepOptions{
+		DatabaseName:  "db",
+		ClientOptions: []xata.ClientOption{xata.WithBaseURL(testSrv.URL), xata.WithAPIKey("test-key")},
+		DryRun:        true,
+	}
+
+	got, err := xatatest.Sweep(context.TODO(), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"xatatest-orphan"}, got)
+	assert.Len(t, svc.branches, 4)
+
+	opts.DryRun = false
+	got, err = xatatest.Sweep(context.TODO(), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"xatatest-orphan"}, got)
+	assert.NotContains(t, svc.branches, "xatatest-orphan")
+	assert.Len(t, svc.branches, 3)
+
+	_, err = xatatest.Sweep(context.TODO(), xatatest.SweepOptions{})
+	assert.Error(t, err)
+}